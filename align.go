@@ -0,0 +1,63 @@
+package spritewell
+
+import "image"
+
+// Align positions images along the cross axis of vertical and
+// horizontal sheets. Vertical sheets use AlignLeft, AlignCenter and
+// AlignRight while horizontal sheets use AlignTop, AlignMiddle and
+// AlignBottom.
+type Align int
+
+const (
+	// AlignDefault inherits Options.Align for an image. On a sheet
+	// it is left or top.
+	AlignDefault Align = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+
+	AlignTop    = AlignLeft
+	AlignMiddle = AlignCenter
+	AlignBottom = AlignRight
+)
+
+// offset returns the offset of an image in free pixels of space
+func (a Align) offset(free int) int {
+	switch a {
+	case AlignCenter:
+		return free / 2
+	case AlignRight:
+		return free
+	}
+	return 0
+}
+
+// align moves every image of a vertical or horizontal pack along the
+// cross axis. Other packs and repeating images are left untouched.
+// Images are aligned within the images that do not repeat, so none
+// are moved onto the bands of repeating images.
+func align(p Packer, pos []Pos, sizes []image.Point, repeats []Repeat, aligns []Align) {
+	var dims image.Point
+	for i, sz := range sizes {
+		if repeats[i] != NoRepeat {
+			continue
+		}
+		if sz.X > dims.X {
+			dims.X = sz.X
+		}
+		if sz.Y > dims.Y {
+			dims.Y = sz.Y
+		}
+	}
+	for i := range pos {
+		if repeats[i] != NoRepeat {
+			continue
+		}
+		switch p.(type) {
+		case Vertical:
+			pos[i].X = aligns[i].offset(dims.X - sizes[i].X)
+		case Horizontal:
+			pos[i].Y = aligns[i].offset(dims.Y - sizes[i].Y)
+		}
+	}
+}
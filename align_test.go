@@ -0,0 +1,76 @@
+package spritewell
+
+import "testing"
+
+func TestAlign(t *testing.T) {
+	imgs := New(&Options{
+		Pack:  "vert",
+		Align: AlignRight,
+	})
+	imgs.Decode("test/139.jpg", "test/pixel.png")
+	if e := (Pos{95, 139}); imgs.PackVertical(1) != e {
+		t.Errorf("got: %v wanted: %v", imgs.PackVertical(1), e)
+	}
+	if e := 0; imgs.X(0) != e {
+		t.Errorf("got: %d wanted: %d", imgs.X(0), e)
+	}
+
+	imgs = New(&Options{
+		Pack: "horz",
+		Images: map[string]ImageOptions{
+			"pixel": {Align: AlignMiddle},
+		},
+	})
+	imgs.Decode("test/139.jpg", "test/pixel.png")
+	if e := (Pos{96, 69}); imgs.GetPack(1) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(1), e)
+	}
+
+	// Other packs ignore alignment
+	imgs = New(&Options{
+		Pack:  "diagonal",
+		Align: AlignRight,
+	})
+	imgs.Decode("test/139.jpg", "test/pixel.png")
+	if e := (Pos{96, 139}); imgs.GetPack(1) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(1), e)
+	}
+}
+
+func TestAlignRepeat(t *testing.T) {
+	imgs := New(&Options{
+		Pack:  "vert",
+		Align: AlignRight,
+		Images: map[string]ImageOptions{
+			"pixel": {Repeat: RepeatY},
+		},
+	})
+	err := imgs.Decode("test/139.jpg", "test/140.jpg", "test/pixel.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{0, 0}); imgs.GetPack(0) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(0), e)
+	}
+	if e := (Pos{96, 0}); imgs.GetPack(2) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(2), e)
+	}
+
+	imgs = New(&Options{
+		Pack:  "horz",
+		Align: AlignBottom,
+		Images: map[string]ImageOptions{
+			"pixel": {Repeat: RepeatX},
+		},
+	})
+	err = imgs.Decode("test/139.jpg", "test/140.jpg", "test/pixel.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{0, 1}); imgs.GetPack(0) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(0), e)
+	}
+	if e := (Pos{0, 140}); imgs.GetPack(2) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(2), e)
+	}
+}
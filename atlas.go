@@ -0,0 +1,121 @@
+package spritewell
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+)
+
+// ErrUnknownAtlasFormat is returned by WriteAtlas for a format that
+// is not AtlasHash or AtlasArray.
+var ErrUnknownAtlasFormat = errors.New("unknown atlas format")
+
+// ErrNoSheet is returned for a sheet the sprite was not split into
+var ErrNoSheet = errors.New("no such sheet")
+
+// AtlasFormat selects the TexturePacker JSON format of WriteAtlas
+type AtlasFormat int
+
+const (
+	// AtlasHash keys frames by the path of the image
+	AtlasHash AtlasFormat = iota
+	// AtlasArray lists frames in the order of Paths
+	AtlasArray
+)
+
+type atlasRect struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type atlasSize struct {
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type atlasFrame struct {
+	Filename         string    `json:"filename,omitempty"`
+	Frame            atlasRect `json:"frame"`
+	Rotated          bool      `json:"rotated"`
+	Trimmed          bool      `json:"trimmed"`
+	SpriteSourceSize atlasRect `json:"spriteSourceSize"`
+	SourceSize       atlasSize `json:"sourceSize"`
+}
+
+type atlasMeta struct {
+	App     string    `json:"app"`
+	Version string    `json:"version"`
+	Image   string    `json:"image"`
+	Format  string    `json:"format"`
+	Size    atlasSize `json:"size"`
+	Scale   string    `json:"scale"`
+}
+
+// WriteAtlas writes the frames of the images packed in sheet as
+// TexturePacker JSON, understood by Phaser and PixiJS among others.
+// Frames are named by Paths. Like TexturePacker, frame is the size of
+// the image before it was rotated clockwise into the sheet.
+func (l *Sprite) WriteAtlas(w io.Writer, format AtlasFormat, sheet int) error {
+	if format != AtlasHash && format != AtlasArray {
+		return ErrUnknownAtlasFormat
+	}
+	opaths, err := l.OutputPaths()
+	if err != nil {
+		return err
+	}
+	if sheet < 0 || sheet >= len(opaths) {
+		return ErrNoSheet
+	}
+
+	var frames []atlasFrame
+	paths := l.Paths()
+	for i := range paths {
+		if l.Sheet(i) != sheet {
+			continue
+		}
+		pos := l.GetPack(i)
+		wd, ht := l.ImageWidth(i), l.ImageHeight(i)
+		off := l.TrimOffset(i)
+		frames = append(frames, atlasFrame{
+			Filename:         filepath.ToSlash(paths[i]),
+			Frame:            atlasRect{pos.X, pos.Y, wd, ht},
+			Rotated:          l.Rotated(i),
+			Trimmed:          l.Trimmed(i),
+			SpriteSourceSize: atlasRect{off.X, off.Y, wd, ht},
+			SourceSize: atlasSize{l.SourceWidth(i),
+				l.SourceHeight(i)},
+		})
+	}
+
+	dims := l.SheetDimensions(sheet)
+	meta := atlasMeta{
+		App:     "https://github.com/wellington/spritewell",
+		Version: "1.0",
+		Image:   filepath.Base(opaths[sheet]),
+		Format:  "RGBA8888",
+		Size:    atlasSize{dims.X, dims.Y},
+		Scale:   "1",
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if format == AtlasArray {
+		return enc.Encode(struct {
+			Frames []atlasFrame `json:"frames"`
+			Meta   atlasMeta    `json:"meta"`
+		}{frames, meta})
+	}
+	hash := make(map[string]atlasFrame, len(frames))
+	for _, f := range frames {
+		name := f.Filename
+		f.Filename = ""
+		hash[name] = f
+	}
+	return enc.Encode(struct {
+		Frames map[string]atlasFrame `json:"frames"`
+		Meta   atlasMeta             `json:"meta"`
+	}{hash, meta})
+}
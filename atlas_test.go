@@ -0,0 +1,63 @@
+package spritewell
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestWriteAtlas(t *testing.T) {
+	imgs := New(&Options{
+		BuildDir:  "test/build",
+		GenImgDir: "test/build/img",
+	})
+	if err := imgs.Decode("test/139.png", "test/140.png"); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := imgs.WriteAtlas(&buf, AtlasHash, 0); err != nil {
+		t.Fatal(err)
+	}
+	var hash struct {
+		Frames map[string]atlasFrame
+		Meta   atlasMeta
+	}
+	if err := json.Unmarshal(buf.Bytes(), &hash); err != nil {
+		t.Fatal(err)
+	}
+	f := hash.Frames["test/140.png"]
+	if e := (atlasRect{0, 139, 96, 140}); f.Frame != e {
+		t.Errorf("got: %v wanted: %v", f.Frame, e)
+	}
+	if e := (atlasSize{96, 140}); f.SourceSize != e {
+		t.Errorf("got: %v wanted: %v", f.SourceSize, e)
+	}
+	if e := (atlasSize{96, 279}); hash.Meta.Size != e {
+		t.Errorf("got: %v wanted: %v", hash.Meta.Size, e)
+	}
+
+	buf.Reset()
+	if err := imgs.WriteAtlas(&buf, AtlasArray, 0); err != nil {
+		t.Fatal(err)
+	}
+	var array struct {
+		Frames []atlasFrame
+	}
+	if err := json.Unmarshal(buf.Bytes(), &array); err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; len(array.Frames) != e {
+		t.Fatalf("got: %d wanted: %d", len(array.Frames), e)
+	}
+	if e := "test/139.png"; array.Frames[0].Filename != e {
+		t.Errorf("got: %s wanted: %s", array.Frames[0].Filename, e)
+	}
+
+	if err := imgs.WriteAtlas(&buf, AtlasFormat(5), 0); err != ErrUnknownAtlasFormat {
+		t.Errorf("got: %v wanted: %v", err, ErrUnknownAtlasFormat)
+	}
+	if err := imgs.WriteAtlas(&buf, AtlasHash, 1); err != ErrNoSheet {
+		t.Errorf("got: %v wanted: %v", err, ErrNoSheet)
+	}
+}
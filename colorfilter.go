@@ -0,0 +1,131 @@
+package spritewell
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownColorOp is returned by Decode for a ColorFilter with an
+// Op that is none of the ColorOp constants.
+var ErrUnknownColorOp = errors.New("unknown color filter")
+
+// ColorOp is the operation of a ColorFilter
+type ColorOp string
+
+const (
+	Grayscale  ColorOp = "grayscale"  // removes the color by luminance
+	Tint       ColorOp = "tint"       // blends towards Color by Amount
+	Opacity    ColorOp = "opacity"    // multiplies alpha by Amount
+	Brightness ColorOp = "brightness" // multiplies colors by Amount
+	Invert     ColorOp = "invert"     // inverts colors, keeping alpha
+)
+
+// ColorFilter changes the colors of an image. Filters are applied in
+// order, see Options.Filters and Options.Variants.
+type ColorFilter struct {
+	Op     ColorOp
+	Color  color.RGBA
+	Amount float64
+}
+
+// filter returns the channels, not premultiplied by alpha, after f
+func (f ColorFilter) filter(c [4]float64) ([4]float64, error) {
+	switch f.Op {
+	case Grayscale:
+		y := 0.299*c[0] + 0.587*c[1] + 0.114*c[2]
+		c[0], c[1], c[2] = y, y, y
+	case Tint:
+		tint := [3]float64{float64(f.Color.R), float64(f.Color.G),
+			float64(f.Color.B)}
+		for i := range tint {
+			c[i] += (tint[i]/0xff - c[i]) * f.Amount
+		}
+	case Opacity:
+		c[3] *= f.Amount
+	case Brightness:
+		for i := 0; i < 3; i++ {
+			c[i] *= f.Amount
+		}
+	case Invert:
+		for i := 0; i < 3; i++ {
+			c[i] = 1 - c[i]
+		}
+	default:
+		return c, ErrUnknownColorOp
+	}
+	for i := range c {
+		c[i] = math.Max(0, math.Min(1, c[i]))
+	}
+	return c, nil
+}
+
+// applyFilters returns a copy of img with every filter of fs applied
+func applyFilters(img image.Image, fs []ColorFilter) (image.Image, error) {
+	if len(fs) == 0 {
+		return img, nil
+	}
+	b := img.Bounds()
+	m := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			n := color.NRGBA64Model.Convert(img.At(x, y)).(color.NRGBA64)
+			c := [4]float64{float64(n.R) / 0xffff, float64(n.G) / 0xffff,
+				float64(n.B) / 0xffff, float64(n.A) / 0xffff}
+			var err error
+			for _, f := range fs {
+				if c, err = f.filter(c); err != nil {
+					return nil, err
+				}
+			}
+			i := m.PixOffset(x-b.Min.X, y-b.Min.Y)
+			for k := range c {
+				m.Pix[i+k] = uint8(math.Round(c[k] * 0xff))
+			}
+		}
+	}
+	return m, nil
+}
+
+// variantRel names the variant of rel, ie. arrow.png is arrow-v.png
+func variantRel(rel, v string) string {
+	ext := filepath.Ext(rel)
+	return strings.TrimSuffix(rel, ext) + "-" + v + ext
+}
+
+// addColorVariants appends a copy of every image and its density
+// variants for every Options.Variants. The directives of every copy
+// in queries carry a variant directive naming the filters to apply.
+// bases is set to the rel of the original of every copy.
+func addColorVariants(vs map[string][]ColorFilter, rels, paths []string, densities map[int]map[string]string, queries map[string]url.Values, bases map[string]string) ([]string, []string) {
+	names := make([]string, 0, len(vs))
+	for v := range vs {
+		names = append(names, v)
+	}
+	sort.Strings(names)
+
+	n := len(rels)
+	for _, v := range names {
+		for i := 0; i < n; i++ {
+			rel := variantRel(rels[i], v)
+			query := url.Values{"variant": {v}}
+			for key, vals := range queries[rels[i]] {
+				query[key] = vals
+			}
+			bases[rel], queries[rel] = rels[i], query
+			rels = append(rels, rel)
+			paths = append(paths, paths[i])
+			for _, d := range densities {
+				if path, ok := d[rels[i]]; ok {
+					d[rel] = path
+				}
+			}
+		}
+	}
+	return rels, paths
+}
@@ -0,0 +1,79 @@
+package spritewell
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestApplyFilters(t *testing.T) {
+	m := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	m.Set(0, 0, color.NRGBA{200, 100, 0, 255})
+
+	tests := []struct {
+		fs   []ColorFilter
+		want color.NRGBA
+	}{
+		{[]ColorFilter{{Op: Grayscale}}, color.NRGBA{118, 118, 118, 255}},
+		{[]ColorFilter{{Op: Tint, Color: color.RGBA{0, 0, 255, 255},
+			Amount: 0.5}}, color.NRGBA{100, 50, 128, 255}},
+		{[]ColorFilter{{Op: Opacity, Amount: 0.5}}, color.NRGBA{200, 100, 0, 128}},
+		{[]ColorFilter{{Op: Brightness, Amount: 1.5}}, color.NRGBA{255, 150, 0, 255}},
+		{[]ColorFilter{{Op: Invert}}, color.NRGBA{55, 155, 255, 255}},
+		{[]ColorFilter{{Op: Invert}, {Op: Invert}}, color.NRGBA{200, 100, 0, 255}},
+	}
+	for _, tt := range tests {
+		img, err := applyFilters(m, tt.fs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c := img.At(0, 0); c != tt.want {
+			t.Errorf("%v got: %v wanted: %v", tt.fs, c, tt.want)
+		}
+	}
+
+	_, err := applyFilters(m, []ColorFilter{{Op: "blur"}})
+	if err != ErrUnknownColorOp {
+		t.Errorf("got: %v wanted: %v", err, ErrUnknownColorOp)
+	}
+}
+
+func TestSpriteVariants(t *testing.T) {
+	imgs := New(&Options{
+		Variants: map[string][]ColorFilter{
+			"disabled": {{Op: Grayscale}},
+			"faded":    {{Op: Opacity, Amount: 0.5}},
+		},
+	})
+	if err := imgs.Decode("test/many/bird.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if e := 3; imgs.Len() != e {
+		t.Fatalf("got: %d wanted: %d", imgs.Len(), e)
+	}
+	pos := imgs.Lookup("bird-disabled")
+	if pos < 0 {
+		t.Fatalf("bird-disabled not found in %v", imgs.Paths())
+	}
+	if e := (Pos{0, 150}); imgs.GetPack(pos) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(pos), e)
+	}
+	img := imgs.imgs[pos]
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.R != c.G || c.G != c.B {
+				t.Fatalf("%d,%d got: %v wanted gray", x, y, c)
+			}
+		}
+	}
+	if imgs.Lookup("bird-faded") < 0 {
+		t.Errorf("bird-faded not found in %v", imgs.Paths())
+	}
+
+	imgs = New(&Options{Filters: []ColorFilter{{Op: "blur"}}})
+	if err := imgs.Decode("test/139.png"); err != ErrUnknownColorOp {
+		t.Errorf("got: %v wanted: %v", err, ErrUnknownColorOp)
+	}
+}
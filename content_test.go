@@ -0,0 +1,72 @@
+package spritewell
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestContentHash(t *testing.T) {
+	tdir, err := ioutil.TempDir("", "TestContentHash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	copyFile := func(src string) {
+		bs, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(filepath.Join(tdir, "icon.png"), bs, 0644)
+	}
+
+	paths := make([]string, 2)
+	plain := make([]string, 2)
+	for i, src := range []string{"test/139.png", "test/140.png"} {
+		copyFile(src)
+		imgs := New(&Options{ImageDir: tdir, BuildDir: tdir, GenImgDir: tdir, ContentHash: true})
+		if err := imgs.Decode("icon.png"); err != nil {
+			t.Fatal(err)
+		}
+		paths[i], _ = imgs.OutputPath()
+
+		imgs = New(&Options{ImageDir: tdir, BuildDir: tdir, GenImgDir: tdir})
+		imgs.Decode("icon.png")
+		plain[i], _ = imgs.OutputPath()
+	}
+	if paths[0] == paths[1] {
+		t.Errorf("changed pixels share path %s", paths[0])
+	}
+	if plain[0] != plain[1] {
+		t.Errorf("got: %s wanted: %s", plain[1], plain[0])
+	}
+	if paths[0] == plain[0] {
+		t.Errorf("content hashed path equals plain path %s", plain[0])
+	}
+}
+
+func TestContentHashLayout(t *testing.T) {
+	seen := make(map[string]*Options)
+	for _, opts := range []*Options{
+		{},
+		{Align: AlignRight},
+		{Extrude: 1},
+		{OrderList: []string{"pixel"}},
+		{Pack: "maxrects", Rotate: true},
+	} {
+		opts.ContentHash = true
+		imgs := New(opts)
+		if err := imgs.Decode("test/139.png", "test/pixel.png"); err != nil {
+			t.Fatal(err)
+		}
+		path, err := imgs.OutputPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if prev, ok := seen[path]; ok {
+			t.Errorf("%+v and %+v share path %s", prev, opts, path)
+		}
+		seen[path] = opts
+	}
+}
@@ -0,0 +1,198 @@
+package spritewell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// ErrRotatedImage is returned by the stylesheet writers when an image
+// is rotated in the sheet, CSS backgrounds can not turn it back.
+var ErrRotatedImage = errors.New("stylesheets can not show rotated images")
+
+// ErrDuplicateName is returned by the stylesheet writers when two
+// images share a name, ie. a/icon.png and b/icon.png.
+var ErrDuplicateName = errors.New("images share a stylesheet name")
+
+// CSSOptions configures the stylesheet written by WriteCSS
+type CSSOptions struct {
+	// BaseClass is the class setting the background-image shared by
+	// every image, default "sprite"
+	BaseClass string
+	// Selector of every image. "{base}" is replaced by BaseClass and
+	// "{name}" by the name of the image, default ".{base}-{name}"
+	Selector string
+}
+
+// pseudoSuffixes map file name suffixes to the pseudo-class of the
+// image they are a state of, ie. button_hover.png is button:hover
+var pseudoSuffixes = []string{"hover", "active"}
+
+// spriteName returns the name of the image at path for stylesheets.
+// It is the base name Lookup accepts with every character invalid in
+// a CSS identifier replaced by "-".
+func spriteName(path string) string {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z',
+			r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, base)
+}
+
+// styleNames returns the spriteName of every image in Paths order.
+// Names must be unique and images must not be rotated.
+func (l *Sprite) styleNames() ([]string, error) {
+	paths := l.Paths()
+	names := make([]string, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for i, path := range paths {
+		if l.Rotated(i) {
+			return nil, ErrRotatedImage
+		}
+		names[i] = spriteName(path)
+		if seen[names[i]] {
+			return nil, ErrDuplicateName
+		}
+		seen[names[i]] = true
+	}
+	return names, nil
+}
+
+// px formats a CSS length in pixels
+func px(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	return fmt.Sprintf("%dpx", n)
+}
+
+// pseudoClass splits a name with a pseudo-class suffix like
+// "button_hover" into "button" and "hover"
+func pseudoClass(name string) (string, string) {
+	for _, s := range pseudoSuffixes {
+		if strings.HasSuffix(name, "_"+s) {
+			return strings.TrimSuffix(name, "_"+s), s
+		}
+	}
+	return name, ""
+}
+
+// WriteCSS writes a stylesheet with a rule for every image. The base
+// class sets the background-image, the rule of each image its size
+// and background-position. Images named with a _hover or _active
+// suffix are written as that pseudo-class of the image without the
+// suffix, if it is part of the sprite. The url is OutputPath, so the
+// stylesheet is expected in BuildDir.
+//
+// ErrRotatedImage is returned if any image is rotated and
+// ErrDuplicateName if two images share a name.
+//
+// With Options.Trim a trimmed image is sized to its trimmed pixels and
+// a margin restores the transparent edges removed, so it takes up the
+// space of its source.
+func (l *Sprite) WriteCSS(w io.Writer, opts *CSSOptions) error {
+	var o CSSOptions
+	if opts != nil {
+		o = *opts
+	}
+	if len(o.BaseClass) == 0 {
+		o.BaseClass = "sprite"
+	}
+	if len(o.Selector) == 0 {
+		o.Selector = ".{base}-{name}"
+	}
+	selector := func(name string) string {
+		return strings.NewReplacer("{base}", o.BaseClass,
+			"{name}", name).Replace(o.Selector)
+	}
+
+	opaths, err := l.OutputPaths()
+	if err != nil {
+		return err
+	}
+	url := func(path string) string {
+		return fmt.Sprintf(`url("%s")`, filepath.ToSlash(path))
+	}
+
+	names, err := l.styleNames()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(names))
+	for _, name := range names {
+		known[name] = true
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, ".%s {\n  background-image: %s;\n"+
+		"  background-repeat: no-repeat;\n}\n", o.BaseClass, url(opaths[0]))
+	for i, name := range names {
+		sel := selector(name)
+		if base, pseudo := pseudoClass(name); len(pseudo) > 0 && known[base] {
+			sel = selector(base) + ":" + pseudo
+		}
+		pos := l.GetPack(i)
+		fmt.Fprintf(bw, "\n%s {\n", sel)
+		if sheet := l.Sheet(i); sheet > 0 {
+			fmt.Fprintf(bw, "  background-image: %s;\n", url(opaths[sheet]))
+		}
+		if r := l.Repeat(i); r != NoRepeat {
+			fmt.Fprintf(bw, "  background-repeat: %s;\n", r)
+		}
+		w, h := l.ImageWidth(i), l.ImageHeight(i)
+		fmt.Fprintf(bw, "  width: %s;\n  height: %s;\n", px(w), px(h))
+		if l.Trimmed(i) {
+			off := l.TrimOffset(i)
+			fmt.Fprintf(bw, "  margin: %s %s %s %s;\n", px(off.Y),
+				px(l.SourceWidth(i)-off.X-w),
+				px(l.SourceHeight(i)-off.Y-h), px(off.X))
+		}
+		fmt.Fprintf(bw, "  background-position: %s %s;\n}\n",
+			px(-pos.X), px(-pos.Y))
+	}
+	return bw.Flush()
+}
+
+// WriteCustomProperties writes a :root rule declaring the position
+// and size of every image as the custom properties --prefix-name-x,
+// -y, -w and -h, default prefix "sprite". x and y are the position in
+// the sheet, negate them for background-position. The url of the
+// sheet is --prefix-url, overriding it swaps the sheet at runtime.
+// Images on further sheets have their own --prefix-name-url.
+// It returns the same errors as WriteCSS.
+func (l *Sprite) WriteCustomProperties(w io.Writer, prefix string) error {
+	if len(prefix) == 0 {
+		prefix = "sprite"
+	}
+	entries, err := l.mapEntries()
+	if err != nil {
+		return err
+	}
+	opaths, err := l.OutputPaths()
+	if err != nil {
+		return err
+	}
+	first := filepath.ToSlash(opaths[0])
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, ":root {\n  --%s-url: url(\"%s\");\n", prefix, first)
+	for _, e := range entries {
+		v := prefix + "-" + e.name
+		fmt.Fprintf(bw, "  --%s-x: %s;\n", v, e.x)
+		fmt.Fprintf(bw, "  --%s-y: %s;\n", v, e.y)
+		fmt.Fprintf(bw, "  --%s-w: %s;\n", v, e.width)
+		fmt.Fprintf(bw, "  --%s-h: %s;\n", v, e.height)
+		if e.url != first {
+			fmt.Fprintf(bw, "  --%s-url: url(\"%s\");\n", v, e.url)
+		}
+	}
+	fmt.Fprint(bw, "}\n")
+	return bw.Flush()
+}
@@ -0,0 +1,164 @@
+package spritewell
+
+import (
+	"bytes"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteCSS(t *testing.T) {
+	tmp := setupTemp("TestWriteCSS")
+	defer tmp.Close()
+	for src, dst := range map[string]string{
+		"test/139.png": "button.png",
+		"test/140.png": "button_hover.png",
+	} {
+		bs, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(filepath.Join(tmp.Image, dst), bs, 0644)
+	}
+
+	imgs := New(&Options{
+		ImageDir:  tmp.Image,
+		BuildDir:  tmp.Build,
+		GenImgDir: tmp.Image,
+		Order:     OrderName,
+	})
+	if err := imgs.Decode("*.png"); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := imgs.OutputPath()
+
+	var buf bytes.Buffer
+	if err := imgs.WriteCSS(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	css := buf.String()
+	for _, e := range []string{
+		".sprite {\n  background-image: url(\"" + filepath.ToSlash(path) + "\");",
+		".sprite-button {\n  width: 96px;\n  height: 139px;\n" +
+			"  background-position: 0 0;\n}",
+		".sprite-button:hover {\n  width: 96px;\n  height: 140px;\n" +
+			"  background-position: 0 -139px;\n}",
+	} {
+		if !strings.Contains(css, e) {
+			t.Errorf("got: %s wanted: %s", css, e)
+		}
+	}
+
+	buf.Reset()
+	imgs.WriteCSS(&buf, &CSSOptions{BaseClass: "icons", Selector: "i.{name}"})
+	css = buf.String()
+	for _, e := range []string{".icons {", "i.button {", "i.button:hover {"} {
+		if !strings.Contains(css, e) {
+			t.Errorf("got: %s wanted: %s", css, e)
+		}
+	}
+}
+
+func TestWriteCustomProperties(t *testing.T) {
+	imgs := New(&Options{
+		BuildDir:  "test/build",
+		GenImgDir: "test/build/img",
+	})
+	if err := imgs.Decode("test/139.png", "test/140.png"); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := imgs.OutputPath()
+
+	var buf bytes.Buffer
+	if err := imgs.WriteCustomProperties(&buf, "icon"); err != nil {
+		t.Fatal(err)
+	}
+	e := ":root {\n" +
+		"  --icon-url: url(\"" + filepath.ToSlash(path) + "\");\n" +
+		"  --icon-139-x: 0;\n  --icon-139-y: 0;\n" +
+		"  --icon-139-w: 96px;\n  --icon-139-h: 139px;\n" +
+		"  --icon-140-x: 0;\n  --icon-140-y: 139px;\n" +
+		"  --icon-140-w: 96px;\n  --icon-140-h: 140px;\n" +
+		"}\n"
+	if buf.String() != e {
+		t.Errorf("got: %s wanted: %s", buf.String(), e)
+	}
+}
+
+func TestWriteCSSTrim(t *testing.T) {
+	imgs := New(&Options{
+		BuildDir:  "test/build",
+		GenImgDir: "test/build/img",
+		Trim:      true,
+	})
+	if err := imgs.Decode("test/trim/margin.png"); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := imgs.WriteCSS(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	// 32x24 with a 20x10 block at 6,4
+	e := ".sprite-margin {\n  width: 20px;\n  height: 10px;\n" +
+		"  margin: 4px 6px 10px 6px;\n  background-position: 0 0;\n}"
+	if !strings.Contains(buf.String(), e) {
+		t.Errorf("got: %s wanted: %s", buf.String(), e)
+	}
+}
+
+func TestWriteCSSErrors(t *testing.T) {
+	tmp := setupTemp("TestWriteCSSErrors")
+	defer tmp.Close()
+	for _, dir := range []string{"a", "b"} {
+		os.MkdirAll(filepath.Join(tmp.Build, dir), 0700)
+	}
+	writePNG(t, filepath.Join(tmp.Build, "a", "icon.png"), 4, 4, color.Black)
+	writePNG(t, filepath.Join(tmp.Build, "b", "icon.png"), 2, 2, color.White)
+	writePNG(t, filepath.Join(tmp.Build, "wide.png"), 100, 10, color.Black)
+	writePNG(t, filepath.Join(tmp.Build, "tall.png"), 10, 100, color.White)
+
+	write := map[string]func(*Sprite) error{
+		"css": func(l *Sprite) error {
+			return l.WriteCSS(ioutil.Discard, nil)
+		},
+		"map": func(l *Sprite) error {
+			return l.WriteMap(ioutil.Discard, SCSS, "")
+		},
+		"properties": func(l *Sprite) error {
+			return l.WriteCustomProperties(ioutil.Discard, "")
+		},
+	}
+	for kind, fn := range write {
+		imgs := New(&Options{
+			ImageDir:  tmp.Build,
+			BuildDir:  tmp.Build,
+			GenImgDir: tmp.Image,
+		})
+		if err := imgs.Decode("*/icon.png"); err != nil {
+			t.Fatal(err)
+		}
+		if e := ErrDuplicateName; fn(imgs) != e {
+			t.Errorf("%s got: %v wanted: %s", kind, fn(imgs), e)
+		}
+
+		imgs = New(&Options{
+			ImageDir:  tmp.Build,
+			BuildDir:  tmp.Build,
+			GenImgDir: tmp.Image,
+			Pack:      "maxrects",
+			Rotate:    true,
+		})
+		if err := imgs.Decode("wide.png", "tall.png"); err != nil {
+			t.Fatal(err)
+		}
+		if !imgs.Rotated(0) && !imgs.Rotated(1) {
+			t.Fatal("expected a rotated image")
+		}
+		if e := ErrRotatedImage; fn(imgs) != e {
+			t.Errorf("%s got: %v wanted: %s", kind, fn(imgs), e)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package spritewell
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+)
+
+// pixelHash returns a hash of the size and pixels of img. Images
+// with equal hashes are packed only once.
+func pixelHash(img image.Image) string {
+	hasher := md5.New()
+	bounds := img.Bounds()
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint32(buf[:4], uint32(bounds.Dx()))
+	binary.BigEndian.PutUint32(buf[4:], uint32(bounds.Dy()))
+	hasher.Write(buf)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			binary.BigEndian.PutUint16(buf[0:], uint16(r))
+			binary.BigEndian.PutUint16(buf[2:], uint16(g))
+			binary.BigEndian.PutUint16(buf[4:], uint16(b))
+			binary.BigEndian.PutUint16(buf[6:], uint16(a))
+			hasher.Write(buf)
+		}
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// dedupe maps every hash to the index of the first equal hash. The
+// indices of the first occurrences are returned in order.
+func dedupe(hashes []string) (canon []int, unique []int) {
+	seen := make(map[string]int, len(hashes))
+	canon = make([]int, len(hashes))
+	for i, h := range hashes {
+		if j, ok := seen[h]; ok {
+			canon[i] = j
+			continue
+		}
+		seen[h] = i
+		canon[i] = i
+		unique = append(unique, i)
+	}
+	return
+}
+
+// Duplicates reports the files with identical pixels that share a
+// single position in the sheet. Every group lists the paths, as
+// returned by Paths, that collapsed together.
+func (l *Sprite) Duplicates() [][]string {
+	l.goImagesMu.RLock()
+	canon, _ := dedupe(densityHashes(l.hashes, l.densities))
+	l.goImagesMu.RUnlock()
+	paths := l.Paths()
+
+	groups := make(map[int][]string)
+	var order []int
+	for i, c := range canon {
+		if i >= len(paths) {
+			break
+		}
+		if c != i && len(groups[c]) == 0 {
+			groups[c] = append(groups[c], paths[c])
+			order = append(order, c)
+		}
+		if c != i {
+			groups[c] = append(groups[c], paths[i])
+		}
+	}
+	dups := make([][]string, len(order))
+	for i, c := range order {
+		dups[i] = groups[c]
+	}
+	return dups
+}
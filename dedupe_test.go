@@ -0,0 +1,59 @@
+package spritewell
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDedupe(t *testing.T) {
+	canon, unique := dedupe([]string{"a", "b", "a", "c", "b"})
+	want := []int{0, 1, 0, 3, 1}
+	for i := range want {
+		if canon[i] != want[i] {
+			t.Errorf("%d got: %d wanted: %d", i, canon[i], want[i])
+		}
+	}
+	if e := 3; len(unique) != e {
+		t.Errorf("got: %d wanted: %d", len(unique), e)
+	}
+}
+
+func TestSpriteDuplicates(t *testing.T) {
+	tdir, err := ioutil.TempDir("", "TestSpriteDuplicates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tdir)
+	for _, name := range []string{"a.png", "b.png", "copy.png"} {
+		src := "test/139.png"
+		if name == "b.png" {
+			src = "test/140.png"
+		}
+		bs, err := ioutil.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.WriteFile(filepath.Join(tdir, name), bs, 0644)
+	}
+
+	imgs := New(&Options{ImageDir: tdir})
+	if err := imgs.Decode("*.png"); err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{96, 279}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+	if imgs.GetPack(imgs.Lookup("copy")) != imgs.GetPack(imgs.Lookup("a")) {
+		t.Errorf("copy does not share position of a")
+	}
+
+	dups := imgs.Duplicates()
+	if e := 1; len(dups) != e {
+		t.Fatalf("got: %d wanted: %d", len(dups), e)
+	}
+	if e := "a.png|copy.png"; dups[0][0]+"|"+dups[0][1] != e {
+		t.Errorf("got: %v wanted: %s", dups[0], e)
+	}
+}
@@ -0,0 +1,250 @@
+package spritewell
+
+import (
+	"fmt"
+	"image"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// density holds the variants of every image at a pixel density. They
+// are drawn in a sheet of their own, every position multiplied by
+// scale.
+type density struct {
+	scale int
+	// paths of the variants, empty if the image has none and is
+	// scaled up or was downscaled from it instead
+	paths  []string
+	imgs   []image.Image
+	hashes []string
+}
+
+var densitySuffix = regexp.MustCompile(`@([0-9]+)x$`)
+
+// densityHashes returns the hashes of every image joined with those of
+// its variants, so images are only deduplicated when they are equal at
+// every density.
+func densityHashes(hashes []string, ds []density) []string {
+	if len(ds) == 0 {
+		return hashes
+	}
+	joined := make([]string, len(hashes))
+	for i := range hashes {
+		joined[i] = hashes[i]
+		for _, d := range ds {
+			joined[i] += "|" + d.hashes[i]
+		}
+	}
+	return joined
+}
+
+// densityOf returns the path of the 1x image of a high density
+// variant named like foo@2x.png and its scale. Other paths are
+// returned as is with scale 1.
+func densityOf(path string) (string, int) {
+	ext := filepath.Ext(path)
+	name := strings.TrimSuffix(path, ext)
+	m := densitySuffix.FindStringSubmatch(name)
+	if m == nil {
+		return path, 1
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n < 2 {
+		return path, 1
+	}
+	return strings.TrimSuffix(name, m[0]) + ext, n
+}
+
+// splitDensities removes high density variants from rels and paths.
+// They are returned by scale and the rel of their 1x image. Variants
+// without a 1x image are left as images of their own.
+func splitDensities(rels, paths []string) ([]string, []string, map[int]map[string]string) {
+	known := make(map[string]bool, len(rels))
+	for _, rel := range rels {
+		known[rel] = true
+	}
+	var (
+		baseRels, basePaths []string
+		variants            = make(map[int]map[string]string)
+	)
+	for i, rel := range rels {
+		base, n := densityOf(rel)
+		if n == 1 || !known[base] {
+			baseRels = append(baseRels, rel)
+			basePaths = append(basePaths, paths[i])
+			continue
+		}
+		if variants[n] == nil {
+			variants[n] = make(map[string]string)
+		}
+		variants[n][base] = paths[i]
+	}
+	return baseRels, basePaths, variants
+}
+
+// decodeDensities decodes the variants of every image in rels with
+// load. A variant must be exactly scale times the size of its source
+// and is trimmed like it. Images without a variant are scaled up.
+func decodeDensities(load func(path, rel string, n int) (image.Image, error), variants map[int]map[string]string, rels []string, imgs []image.Image, sources []image.Point, offsets []Pos) ([]density, error) {
+	scales := make([]int, 0, len(variants))
+	for n := range variants {
+		scales = append(scales, n)
+	}
+	sort.Ints(scales)
+
+	ds := make([]density, len(scales))
+	for k, n := range scales {
+		d := density{
+			scale:  n,
+			paths:  make([]string, len(rels)),
+			imgs:   make([]image.Image, len(rels)),
+			hashes: make([]string, len(rels)),
+		}
+		for i, rel := range rels {
+			path, ok := variants[n][rel]
+			if !ok {
+				d.imgs[i] = upscale(imgs[i], n)
+				continue
+			}
+			img, err := load(path, rel, n)
+			if err != nil {
+				return nil, err
+			}
+			if img.Bounds().Size() != sources[i].Mul(n) {
+				return nil, fmt.Errorf("density: %s is not %dx the size of %s",
+					path, n, rel)
+			}
+			img = cropDensity(img, n, imgs[i].Bounds().Size(), offsets[i])
+			d.paths[i], d.imgs[i], d.hashes[i] = path, img, pixelHash(img)
+		}
+		ds[k] = d
+	}
+	return ds, nil
+}
+
+// cropDensity trims img, a variant at scale n, like its 1x image of
+// size trimmed at offset
+func cropDensity(img image.Image, n int, size image.Point, offset Pos) image.Image {
+	b := img.Bounds()
+	r := image.Rect(0, 0, size.X*n, size.Y*n).
+		Add(b.Min).Add(image.Pt(offset.X*n, offset.Y*n))
+	if s, ok := img.(subImager); ok && r != b {
+		return s.SubImage(r)
+	}
+	return img
+}
+
+// deriveDensity adds the sources of images downscaled at scale n to
+// ds. Variants decoded from files of their own are kept.
+func deriveDensity(ds []density, n int, highs, imgs []image.Image, offsets []Pos) []density {
+	k := 0
+	for k < len(ds) && ds[k].scale < n {
+		k++
+	}
+	if k == len(ds) || ds[k].scale != n {
+		ds = append(ds[:k], append([]density{{
+			scale:  n,
+			paths:  make([]string, len(imgs)),
+			imgs:   make([]image.Image, len(imgs)),
+			hashes: make([]string, len(imgs)),
+		}}, ds[k:]...)...)
+	}
+	d := ds[k]
+	for i := range imgs {
+		if len(d.paths[i]) > 0 {
+			continue
+		}
+		img := cropDensity(highs[i], n, imgs[i].Bounds().Size(), offsets[i])
+		d.imgs[i], d.hashes[i] = img, pixelHash(img)
+	}
+	return ds
+}
+
+// upscale returns img enlarged n times by repeating every pixel
+func upscale(img image.Image, n int) image.Image {
+	b := img.Bounds()
+	m := image.NewRGBA(image.Rect(0, 0, b.Dx()*n, b.Dy()*n))
+	for y := 0; y < m.Rect.Max.Y; y++ {
+		for x := 0; x < m.Rect.Max.X; x++ {
+			m.Set(x, y, img.At(b.Min.X+x/n, b.Min.Y+y/n))
+		}
+	}
+	return m
+}
+
+// scale returns a copy of w with every position and dimension
+// multiplied by n, drawing imgs instead
+func (w work) scale(n int, imgs []image.Image) work {
+	s := w
+	s.imgs = imgs
+	s.extrude = w.extrude * n
+	s.pos = Pos{w.pos.X * n, w.pos.Y * n}
+	s.positions = make([]Pos, len(w.positions))
+	for i, p := range w.positions {
+		s.positions[i] = Pos{p.X * n, p.Y * n}
+	}
+	s.sheetDims = make([]Pos, len(w.sheetDims))
+	for i, p := range w.sheetDims {
+		s.sheetDims[i] = Pos{p.X * n, p.Y * n}
+	}
+	return s
+}
+
+// Densities returns the scales of the high density variants found by
+// Decode in increasing order. Variants are named after their 1x image
+// with a suffix like @2x, ie. foo@2x.png. Every scale has sheets of
+// its own, see DensityPaths.
+func (l *Sprite) Densities() []int {
+	l.goImagesMu.RLock()
+	defer l.goImagesMu.RUnlock()
+	scales := make([]int, len(l.densities))
+	for i, d := range l.densities {
+		scales[i] = d.scale
+	}
+	return scales
+}
+
+// DensityPaths returns the relative path of every sheet at scale.
+// They are OutputPaths suffixed by the scale, ie. @2x. The layout is
+// that of the 1x sheets with every position multiplied by scale.
+func (l *Sprite) DensityPaths(scale int) ([]string, error) {
+	paths, err := l.OutputPaths()
+	if err != nil || scale == 1 {
+		return paths, err
+	}
+	ext := ".png"
+	suffix := "@" + strconv.Itoa(scale) + "x"
+	scaled := make([]string, len(paths))
+	for i := range paths {
+		scaled[i] = strings.TrimSuffix(paths[i], ext) + suffix + ext
+	}
+	return scaled, nil
+}
+
+// BackgroundSize returns the CSS background-size of sheet. It scales
+// the sheets of every density down to the size of the 1x sheet.
+func (l *Sprite) BackgroundSize(sheet int) string {
+	dims := l.SheetDimensions(sheet)
+	return px(dims.X) + " " + px(dims.Y)
+}
+
+// ImageSet returns a CSS image-set() of sheet at every density
+func (l *Sprite) ImageSet(sheet int) (string, error) {
+	scales := append([]int{1}, l.Densities()...)
+	set := make([]string, len(scales))
+	for i, n := range scales {
+		paths, err := l.DensityPaths(n)
+		if err != nil {
+			return "", err
+		}
+		if sheet < 0 || sheet >= len(paths) {
+			return "", ErrNoSheet
+		}
+		set[i] = fmt.Sprintf(`url("%s") %dx`,
+			filepath.ToSlash(paths[sheet]), n)
+	}
+	return "image-set(" + strings.Join(set, ", ") + ")", nil
+}
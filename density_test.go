@@ -0,0 +1,164 @@
+package spritewell
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePNG(t *testing.T, path string, w, h int, c color.Color) {
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			m.Set(x, y, c)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDensityOf(t *testing.T) {
+	tests := []struct {
+		path, base string
+		scale      int
+	}{
+		{"a/foo@2x.png", "a/foo.png", 2},
+		{"foo@3x.png", "foo.png", 3},
+		{"foo.png", "foo.png", 1},
+		{"foo@1x.png", "foo@1x.png", 1},
+		{"foo@2.png", "foo@2.png", 1},
+	}
+	for _, tt := range tests {
+		base, scale := densityOf(tt.path)
+		if base != tt.base || scale != tt.scale {
+			t.Errorf("got: %s %d wanted: %s %d", base, scale,
+				tt.base, tt.scale)
+		}
+	}
+}
+
+func TestSpriteDensities(t *testing.T) {
+	tmp := setupTemp("TestSpriteDensities")
+	defer tmp.Close()
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	writePNG(t, filepath.Join(tmp.Build, "a.png"), 2, 3, red)
+	writePNG(t, filepath.Join(tmp.Build, "a@2x.png"), 4, 6, blue)
+	writePNG(t, filepath.Join(tmp.Build, "b.png"), 3, 2, red)
+
+	imgs := New(&Options{
+		ImageDir:  tmp.Build,
+		BuildDir:  tmp.Build,
+		GenImgDir: tmp.Image,
+	})
+	if err := imgs.Decode("*.png"); err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; imgs.Len() != e {
+		t.Fatalf("got: %d wanted: %d", imgs.Len(), e)
+	}
+	if ds := imgs.Densities(); len(ds) != 1 || ds[0] != 2 {
+		t.Fatalf("got: %v wanted: [2]", ds)
+	}
+	if e := "3px 5px"; imgs.BackgroundSize(0) != e {
+		t.Errorf("got: %s wanted: %s", imgs.BackgroundSize(0), e)
+	}
+	path, _ := imgs.OutputPath()
+	dpaths, _ := imgs.DensityPaths(2)
+	set, err := imgs.ImageSet(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := `image-set(url("` + path + `") 1x, url("` + dpaths[0] +
+		`") 2x)`; set != e {
+		t.Errorf("got: %s wanted: %s", set, e)
+	}
+
+	if _, err := imgs.Export(); err != nil {
+		t.Fatal(err)
+	}
+	if err := imgs.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(filepath.Join(tmp.Image, filepath.Base(dpaths[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	m, err := png.Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := image.Rect(0, 0, 6, 10); m.Bounds() != e {
+		t.Errorf("got: %v wanted: %v", m.Bounds(), e)
+	}
+	// The variant of a replaces it, b is scaled up
+	b := imgs.GetPack(imgs.Lookup("b"))
+	if c := color.RGBAModel.Convert(m.At(3, 5)); c != blue {
+		t.Errorf("got: %v wanted: %v", c, blue)
+	}
+	if c := color.RGBAModel.Convert(m.At(b.X*2+5, b.Y*2+3)); c != red {
+		t.Errorf("got: %v wanted: %v", c, red)
+	}
+}
+
+func TestSpriteDensitiesDedupe(t *testing.T) {
+	tmp := setupTemp("TestSpriteDensitiesDedupe")
+	defer tmp.Close()
+	red := color.RGBA{255, 0, 0, 255}
+	writePNG(t, filepath.Join(tmp.Build, "a.png"), 2, 2, red)
+	writePNG(t, filepath.Join(tmp.Build, "a@2x.png"), 4, 4,
+		color.RGBA{0, 0, 255, 255})
+	writePNG(t, filepath.Join(tmp.Build, "b.png"), 2, 2, red)
+	writePNG(t, filepath.Join(tmp.Build, "b@2x.png"), 4, 4,
+		color.RGBA{0, 255, 0, 255})
+
+	imgs := New(&Options{ImageDir: tmp.Build})
+	if err := imgs.Decode("*.png"); err != nil {
+		t.Fatal(err)
+	}
+	// Equal at 1x, but not at 2x
+	if imgs.GetPack(0) == imgs.GetPack(1) {
+		t.Errorf("images with different variants share %v", imgs.GetPack(0))
+	}
+	if d := imgs.Duplicates(); len(d) != 0 {
+		t.Errorf("got: %v wanted no duplicates", d)
+	}
+}
+
+func TestSpriteDensitiesContentHash(t *testing.T) {
+	tmp := setupTemp("TestSpriteDensitiesContentHash")
+	defer tmp.Close()
+	writePNG(t, filepath.Join(tmp.Build, "a.png"), 2, 2, color.Black)
+
+	var paths []string
+	for _, c := range []color.Color{color.Black, color.White} {
+		writePNG(t, filepath.Join(tmp.Build, "a@2x.png"), 4, 4, c)
+		imgs := New(&Options{
+			ImageDir:    tmp.Build,
+			BuildDir:    tmp.Build,
+			GenImgDir:   tmp.Image,
+			ContentHash: true,
+		})
+		if err := imgs.Decode("*.png"); err != nil {
+			t.Fatal(err)
+		}
+		dpaths, err := imgs.DensityPaths(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, dpaths[0])
+	}
+	if paths[0] == paths[1] {
+		t.Errorf("changed @2x variant shares path %s", paths[0])
+	}
+}
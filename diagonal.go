@@ -0,0 +1,51 @@
+package spritewell
+
+import "image"
+
+// Diagonal places every image in a row and a column of its own,
+// stepping from the top left to the bottom right of the sheet. An
+// element larger than its image never shows a neighbour to the
+// right of or below the image.
+type Diagonal struct{}
+
+// Pack implements Packer
+func (Diagonal) Pack(sizes []image.Point, padding int) ([]Pos, Pos, error) {
+	pos := make([]Pos, len(sizes))
+	var dims Pos
+	for i, sz := range sizes {
+		// No padding on the outside of the image
+		if i > 0 {
+			dims.X += padding
+			dims.Y += padding
+		}
+		pos[i] = Pos{dims.X, dims.Y}
+		dims.X += sz.X
+		dims.Y += sz.Y
+	}
+	return pos, dims, nil
+}
+
+// ZigZag places every image in a row and a column of its own like
+// Diagonal, but alternates images between the left and right side of
+// the sheet. Rows step down the sheet while columns fill in from
+// both edges towards the middle.
+type ZigZag struct{}
+
+// Pack implements Packer
+func (ZigZag) Pack(sizes []image.Point, padding int) ([]Pos, Pos, error) {
+	_, dims, _ := Diagonal{}.Pack(sizes, padding)
+	pos := make([]Pos, len(sizes))
+	left, right, y := 0, dims.X, 0
+	for i, sz := range sizes {
+		if i%2 == 0 {
+			pos[i] = Pos{left, y}
+			left += sz.X + padding
+		} else {
+			right -= sz.X
+			pos[i] = Pos{right, y}
+			right -= padding
+		}
+		y += sz.Y + padding
+	}
+	return pos, dims, nil
+}
@@ -0,0 +1,63 @@
+package spritewell
+
+import (
+	"image"
+	"testing"
+)
+
+// exclusive reports whether every image has rows and columns to
+// itself
+func exclusive(sizes []image.Point, pos []Pos) bool {
+	for i := range pos {
+		for j := i + 1; j < len(pos); j++ {
+			if pos[i].X < pos[j].X+sizes[j].X && pos[j].X < pos[i].X+sizes[i].X {
+				return false
+			}
+			if pos[i].Y < pos[j].Y+sizes[j].Y && pos[j].Y < pos[i].Y+sizes[i].Y {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestDiagonal(t *testing.T) {
+	sizes := []image.Point{{10, 20}, {30, 5}, {8, 8}}
+	for _, p := range []Packer{Diagonal{}, ZigZag{}} {
+		pos, dims, err := p.Pack(sizes, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e := (Pos{52, 37}); dims != e {
+			t.Errorf("%T got: %v wanted: %v", p, dims, e)
+		}
+		if !exclusive(sizes, pos) {
+			t.Errorf("%T images share rows or columns: %v", p, pos)
+		}
+		for i := range pos {
+			if pos[i].X+sizes[i].X > dims.X || pos[i].Y+sizes[i].Y > dims.Y {
+				t.Errorf("%T image %d outside of sheet", p, i)
+			}
+		}
+	}
+
+	pos, _, _ := Diagonal{}.Pack(sizes, 2)
+	if e := (Pos{44, 29}); pos[2] != e {
+		t.Errorf("got: %v wanted: %v", pos[2], e)
+	}
+	pos, _, _ = ZigZag{}.Pack(sizes, 2)
+	if e := (Pos{22, 22}); pos[1] != e {
+		t.Errorf("got: %v wanted: %v", pos[1], e)
+	}
+}
+
+func TestSpriteDiagonal(t *testing.T) {
+	imgs := New(&Options{Pack: "diagonal"})
+	imgs.Decode("test/139.jpg", "test/140.jpg")
+	if e := (Pos{192, 279}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+	if e := (Pos{96, 139}); imgs.GetPack(1) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(1), e)
+	}
+}
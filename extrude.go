@@ -0,0 +1,46 @@
+package spritewell
+
+import (
+	"image"
+	"image/draw"
+)
+
+// extrude repeats the edge pixels of img, drawn at pt in dst, n
+// pixels outward on every side. Corners are filled with the corner
+// pixels of img.
+func extrude(dst draw.Image, img image.Image, pt image.Point, n int) {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return
+	}
+	for k := 1; k <= n; k++ {
+		// Top and bottom rows
+		draw.Draw(dst, image.Rect(pt.X, pt.Y-k, pt.X+w, pt.Y-k+1),
+			img, b.Min, draw.Src)
+		draw.Draw(dst, image.Rect(pt.X, pt.Y+h+k-1, pt.X+w, pt.Y+h+k),
+			img, image.Pt(b.Min.X, b.Max.Y-1), draw.Src)
+		// Left and right columns
+		draw.Draw(dst, image.Rect(pt.X-k, pt.Y, pt.X-k+1, pt.Y+h),
+			img, b.Min, draw.Src)
+		draw.Draw(dst, image.Rect(pt.X+w+k-1, pt.Y, pt.X+w+k, pt.Y+h),
+			img, image.Pt(b.Max.X-1, b.Min.Y), draw.Src)
+	}
+
+	corners := []struct {
+		r   image.Rectangle
+		src image.Point
+	}{
+		{image.Rect(pt.X-n, pt.Y-n, pt.X, pt.Y), b.Min},
+		{image.Rect(pt.X+w, pt.Y-n, pt.X+w+n, pt.Y),
+			image.Pt(b.Max.X-1, b.Min.Y)},
+		{image.Rect(pt.X-n, pt.Y+h, pt.X, pt.Y+h+n),
+			image.Pt(b.Min.X, b.Max.Y-1)},
+		{image.Rect(pt.X+w, pt.Y+h, pt.X+w+n, pt.Y+h+n),
+			image.Pt(b.Max.X-1, b.Max.Y-1)},
+	}
+	for _, c := range corners {
+		draw.Draw(dst, c.r, image.NewUniform(img.At(c.src.X, c.src.Y)),
+			image.Point{}, draw.Src)
+	}
+}
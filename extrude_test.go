@@ -0,0 +1,37 @@
+package spritewell
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestExtrude(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	blue := color.RGBA{0, 0, 255, 255}
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, red)
+	img.Set(1, 0, blue)
+	img.Set(0, 1, blue)
+	img.Set(1, 1, blue)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 6, 6))
+	extrude(dst, img, image.Pt(2, 2), 2)
+
+	tests := []struct {
+		x, y int
+		c    color.RGBA
+	}{
+		{2, 0, red},  // top edge
+		{0, 2, red},  // left edge
+		{0, 0, red},  // top left corner
+		{5, 5, blue}, // bottom right corner
+		{3, 5, blue}, // bottom edge
+		{2, 2, color.RGBA{}},
+	}
+	for _, tt := range tests {
+		if c := dst.At(tt.x, tt.y); c != tt.c {
+			t.Errorf("%d,%d got: %v wanted: %v", tt.x, tt.y, c, tt.c)
+		}
+	}
+}
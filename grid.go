@@ -0,0 +1,129 @@
+package spritewell
+
+import (
+	"errors"
+	"image"
+	"math"
+)
+
+// ErrCellTooSmall is returned when an image does not fit in the
+// fixed cell size of a grid.
+var ErrCellTooSmall = errors.New("image is larger than grid cell")
+
+// Anchor positions an image inside a grid cell larger than itself
+type Anchor int
+
+const (
+	// AnchorCenter centers the image in its cell. This is the default.
+	AnchorCenter Anchor = iota
+	AnchorTopLeft
+	AnchorTop
+	AnchorTopRight
+	AnchorLeft
+	AnchorRight
+	AnchorBottomLeft
+	AnchorBottom
+	AnchorBottomRight
+)
+
+// offset returns the position of an image of size sz inside a cell
+func (a Anchor) offset(cell, sz image.Point) Pos {
+	dx, dy := cell.X-sz.X, cell.Y-sz.Y
+	var p Pos
+	switch a {
+	case AnchorTopLeft, AnchorLeft, AnchorBottomLeft:
+	case AnchorTopRight, AnchorRight, AnchorBottomRight:
+		p.X = dx
+	default:
+		p.X = dx / 2
+	}
+	switch a {
+	case AnchorTopLeft, AnchorTop, AnchorTopRight:
+	case AnchorBottomLeft, AnchorBottom, AnchorBottomRight:
+		p.Y = dy
+	default:
+		p.Y = dy / 2
+	}
+	return p
+}
+
+// Grid lays images out in rows of fixed size cells. When Columns is
+// zero a roughly square grid is used. When CellWidth or CellHeight is
+// zero the size of the largest image is used.
+type Grid struct {
+	Columns               int
+	CellWidth, CellHeight int
+	Anchor                Anchor
+}
+
+// Pack implements Packer
+func (g Grid) Pack(sizes []image.Point, padding int) ([]Pos, Pos, error) {
+	if len(sizes) == 0 {
+		return nil, Pos{}, nil
+	}
+	cell := image.Pt(g.CellWidth, g.CellHeight)
+	for _, sz := range sizes {
+		if g.CellWidth == 0 && sz.X > cell.X {
+			cell.X = sz.X
+		}
+		if g.CellHeight == 0 && sz.Y > cell.Y {
+			cell.Y = sz.Y
+		}
+		if sz.X > cell.X || sz.Y > cell.Y {
+			return nil, Pos{}, ErrCellTooSmall
+		}
+	}
+
+	cols := g.Columns
+	if cols <= 0 {
+		cols = int(math.Ceil(math.Sqrt(float64(len(sizes)))))
+	}
+	if cols > len(sizes) {
+		cols = len(sizes)
+	}
+	rows := (len(sizes) + cols - 1) / cols
+
+	pos := make([]Pos, len(sizes))
+	for i, sz := range sizes {
+		off := g.Anchor.offset(cell, sz)
+		pos[i] = Pos{
+			X: (i%cols)*(cell.X+padding) + off.X,
+			Y: (i/cols)*(cell.Y+padding) + off.Y,
+		}
+	}
+	// No padding on the outside of the sheet
+	dims := Pos{
+		X: cols*(cell.X+padding) - padding,
+		Y: rows*(cell.Y+padding) - padding,
+	}
+	return pos, dims, nil
+}
+
+// Cell returns the index of the grid cell, counted in rows from the
+// top left, holding the image at pos. Duplicate images share a cell.
+// -1 is returned if the sprite is not packed as a "grid" or the image
+// repeats, repeating images are placed in bands outside the grid.
+func (l *Sprite) Cell(pos int) int {
+	l.optsMu.RLock()
+	pack := l.opts.Pack
+	l.optsMu.RUnlock()
+	if pack != "grid" {
+		return -1
+	}
+	lay := l.packLayout(pack)
+	if lay.err != nil || pos < 0 || pos >= len(lay.positions) {
+		return -1
+	}
+	c := lay.canon[pos]
+	if lay.repeats[c] != NoRepeat {
+		return -1
+	}
+	// Only unique images that do not repeat are packed in the grid
+	cell := 0
+	for i := 0; i < c; i++ {
+		if lay.canon[i] == i && lay.repeats[i] == NoRepeat {
+			cell++
+		}
+	}
+	return cell
+}
@@ -0,0 +1,110 @@
+package spritewell
+
+import (
+	"image"
+	"testing"
+)
+
+func TestGrid(t *testing.T) {
+	sizes := []image.Point{{16, 16}, {16, 16}, {12, 8}, {16, 16}, {16, 16}}
+
+	pos, dims, err := Grid{Columns: 2}.Pack(sizes, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{34, 52}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+	if e := (Pos{18, 0}); pos[1] != e {
+		t.Errorf("got: %v wanted: %v", pos[1], e)
+	}
+	// Centered in the cell at row 1, column 0
+	if e := (Pos{2, 22}); pos[2] != e {
+		t.Errorf("got: %v wanted: %v", pos[2], e)
+	}
+
+	pos, _, _ = Grid{Columns: 2, Anchor: AnchorBottomRight}.Pack(sizes, 0)
+	if e := (Pos{4, 24}); pos[2] != e {
+		t.Errorf("got: %v wanted: %v", pos[2], e)
+	}
+
+	pos, dims, _ = Grid{CellWidth: 24, CellHeight: 24}.Pack(sizes, 0)
+	if e := (Pos{72, 48}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+	if e := (Pos{54, 8}); pos[2] != e {
+		t.Errorf("got: %v wanted: %v", pos[2], e)
+	}
+
+	_, _, err = Grid{CellWidth: 8, CellHeight: 8}.Pack(sizes, 0)
+	if e := ErrCellTooSmall; err != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+}
+
+func TestSpriteGrid(t *testing.T) {
+	imgs := New(&Options{
+		Pack:    "grid",
+		Columns: 2,
+	})
+	imgs.Decode("test/many/*.jpg")
+
+	if e := (Pos{300, 450}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+	if e := 3; imgs.Cell(3) != e {
+		t.Errorf("got: %d wanted: %d", imgs.Cell(3), e)
+	}
+	if e := (Pos{150, 150}); imgs.GetPack(3) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(3), e)
+	}
+
+	imgs = New(nil)
+	imgs.Decode("test/many/*.jpg")
+	if e := -1; imgs.Cell(0) != e {
+		t.Errorf("got: %d wanted: %d", imgs.Cell(0), e)
+	}
+}
+
+func TestSpriteGridRepeat(t *testing.T) {
+	imgs := New(&Options{
+		Pack:    "grid",
+		Columns: 2,
+		Images: map[string]ImageOptions{
+			"pixel": {Repeat: RepeatX},
+		},
+	})
+	err := imgs.Decode("test/pixel.png", "test/139.jpg", "test/140.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := -1; imgs.Cell(0) != e {
+		t.Errorf("got: %d wanted: %d", imgs.Cell(0), e)
+	}
+	if e := 0; imgs.Cell(1) != e {
+		t.Errorf("got: %d wanted: %d", imgs.Cell(1), e)
+	}
+	if e := 1; imgs.Cell(2) != e {
+		t.Errorf("got: %d wanted: %d", imgs.Cell(2), e)
+	}
+}
+
+func TestSpriteGridPadding(t *testing.T) {
+	imgs := New(&Options{
+		Pack:        "grid",
+		Columns:     2,
+		CellWidth:   96,
+		CellHeight:  140,
+		PaddingLeft: 2,
+	})
+	err := imgs.Decode("test/139.jpg", "test/140.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{196, 140}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+	if e := (Pos{100, 0}); imgs.GetPack(1) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(1), e)
+	}
+}
@@ -0,0 +1,128 @@
+package spritewell
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+)
+
+// manifest records what the sheets of a sprite were built from
+type manifest struct {
+	Options Options         `json:"options"`
+	Sheets  []string        `json:"sheets"`
+	Images  []manifestImage `json:"images"`
+	// Densities holds the hashes of the variants by scale
+	Densities map[int][]string `json:"densities,omitempty"`
+}
+
+type manifestImage struct {
+	Path   string `json:"path"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	Hash   string `json:"hash"`
+}
+
+// manifestFile is the manifest on disk. Sheets holds the size and
+// hash of every encoded sheet, so sheets changed or truncated after
+// they were written are encoded again.
+type manifestFile struct {
+	Inputs json.RawMessage `json:"inputs"`
+	Sheets []sheetDigest   `json:"sheets"`
+}
+
+type sheetDigest struct {
+	Size int    `json:"size"`
+	Hash string `json:"hash"`
+}
+
+// digest returns the sheetDigest of an encoded sheet
+func digest(bs []byte) sheetDigest {
+	sum := md5.Sum(bs)
+	return sheetDigest{Size: len(bs), Hash: hex.EncodeToString(sum[:])}
+}
+
+// writeManifest writes the inputs of a build with the digests of the
+// sheets written
+func writeManifest(path string, inputs []byte, sheets []sheetDigest) error {
+	bs, err := json.MarshalIndent(manifestFile{
+		Inputs: inputs,
+		Sheets: sheets,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bs, 0644)
+}
+
+// manifestPath is the manifest written next to the first sheet
+func manifestPath(sheet string) string {
+	return strings.TrimSuffix(sheet, ".png") + ".json"
+}
+
+// checkManifest encodes the manifest of the images just decoded and
+// reports whether it matches the one on disk. The sheets must match
+// their digests too. Without Options.Manifest it is never fresh.
+func (l *Sprite) checkManifest() (bool, error) {
+	l.optsMu.RLock()
+	m := manifest{Options: *l.opts}
+	l.optsMu.RUnlock()
+
+	l.manifestMu.Lock()
+	defer l.manifestMu.Unlock()
+	l.manifest, l.fresh = nil, false
+	if !m.Options.Manifest {
+		return false, nil
+	}
+
+	sheets, err := l.sheetFiles()
+	if err != nil {
+		return false, err
+	}
+	m.Sheets = sheets
+	paths := l.Paths()
+	l.goImagesMu.RLock()
+	m.Images = make([]manifestImage, len(paths))
+	for i := range paths {
+		m.Images[i] = manifestImage{
+			Path:   paths[i],
+			Width:  l.sources[i].X,
+			Height: l.sources[i].Y,
+			Hash:   l.hashes[i],
+		}
+	}
+	for _, d := range l.densities {
+		if m.Densities == nil {
+			m.Densities = make(map[int][]string)
+		}
+		m.Densities[d.scale] = d.hashes
+	}
+	l.goImagesMu.RUnlock()
+
+	bs, err := json.Marshal(m)
+	if err != nil {
+		return false, err
+	}
+	l.manifest = bs
+
+	var old manifestFile
+	data, err := ioutil.ReadFile(manifestPath(sheets[0]))
+	if err != nil || json.Unmarshal(data, &old) != nil {
+		return false, nil
+	}
+	var inputs bytes.Buffer
+	if json.Compact(&inputs, old.Inputs) != nil ||
+		!bytes.Equal(inputs.Bytes(), bs) || len(old.Sheets) != len(sheets) {
+		return false, nil
+	}
+	for i, sheet := range sheets {
+		data, err := ioutil.ReadFile(sheet)
+		if err != nil || digest(data) != old.Sheets[i] {
+			return false, nil
+		}
+	}
+	l.fresh = true
+	return true, nil
+}
@@ -0,0 +1,67 @@
+package spritewell
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestManifest(t *testing.T) {
+	tmp := setupTemp("TestManifest")
+	defer tmp.Close()
+	opts := &Options{
+		GenImgDir: tmp.Image,
+		BuildDir:  tmp.Build,
+		Manifest:  true,
+	}
+	build := func() string {
+		imgs := New(opts)
+		if err := imgs.Decode("test/139.png", "test/140.png"); err != nil {
+			t.Fatal(err)
+		}
+		abs, err := imgs.Export()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := imgs.Wait(); err != nil {
+			t.Fatal(err)
+		}
+		return abs
+	}
+
+	abs := build()
+	if _, err := os.Stat(manifestPath(abs)); err != nil {
+		t.Fatal(err)
+	}
+	// An old modification time shows whether the sheet is written
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(abs, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if got := build(); got != abs {
+		t.Errorf("got: %s wanted: %s", got, abs)
+	}
+	if fi, _ := os.Stat(abs); !fi.ModTime().Equal(old) {
+		t.Error("up to date sheet was encoded again")
+	}
+
+	// Sheets changed since, ie. truncated by an interrupted build,
+	// are encoded again
+	if err := ioutil.WriteFile(abs, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	build()
+	if fi, _ := os.Stat(abs); fi.Size() == 0 {
+		t.Error("truncated sheet was not encoded again")
+	}
+
+	if err := os.Chtimes(abs, old, old); err != nil {
+		t.Fatal(err)
+	}
+	opts.Align = AlignRight
+	build()
+	if fi, _ := os.Stat(abs); fi.ModTime().Equal(old) {
+		t.Error("changed options did not encode the sheet")
+	}
+}
@@ -0,0 +1,278 @@
+package spritewell
+
+import (
+	"image"
+	"math"
+)
+
+// Heuristic selects the rule MaxRects uses to choose where the next
+// image is placed in the free space of the sheet.
+type Heuristic int
+
+const (
+	// BestShortSideFit places an image in the free area where the
+	// shorter leftover side is smallest. This is the default.
+	BestShortSideFit Heuristic = iota
+	// BestLongSideFit places an image in the free area where the
+	// longer leftover side is smallest.
+	BestLongSideFit
+	// BestAreaFit places an image in the smallest free area it fits.
+	BestAreaFit
+	// BottomLeft places an image as high and then as far left as
+	// possible, Tetris style.
+	BottomLeft
+)
+
+type rect struct {
+	x, y, w, h int
+}
+
+func (r rect) contains(o rect) bool {
+	return o.x >= r.x && o.y >= r.y &&
+		o.x+o.w <= r.x+r.w && o.y+o.h <= r.y+r.h
+}
+
+func (r rect) intersects(o rect) bool {
+	return o.x < r.x+r.w && o.x+o.w > r.x &&
+		o.y < r.y+r.h && o.y+o.h > r.y
+}
+
+// bin tracks the maximal free rectangles of a single sheet
+type bin struct {
+	free      []rect
+	heuristic Heuristic
+}
+
+func newBin(w, h int, heuristic Heuristic) *bin {
+	return &bin{
+		free:      []rect{{0, 0, w, h}},
+		heuristic: heuristic,
+	}
+}
+
+// score returns the placement of a w x h rectangle with the lowest
+// score, lower is better. ok is false if it does not fit.
+func (m *bin) score(w, h int) (best rect, s1, s2 int, ok bool) {
+	s1, s2 = math.MaxInt32, math.MaxInt32
+	for _, f := range m.free {
+		if f.w < w || f.h < h {
+			continue
+		}
+		dw, dh := f.w-w, f.h-h
+		short, long := dw, dh
+		if short > long {
+			short, long = long, short
+		}
+		var a, b int
+		switch m.heuristic {
+		case BestLongSideFit:
+			a, b = long, short
+		case BestAreaFit:
+			a, b = f.w*f.h-w*h, short
+		case BottomLeft:
+			a, b = f.y+h, f.x
+		default:
+			a, b = short, long
+		}
+		if a < s1 || (a == s1 && b < s2) {
+			best = rect{f.x, f.y, w, h}
+			s1, s2, ok = a, b, true
+		}
+	}
+	return
+}
+
+// place removes the area covered by used from the free rectangles
+func (m *bin) place(used rect) {
+	var free []rect
+	for _, f := range m.free {
+		if !f.intersects(used) {
+			free = append(free, f)
+			continue
+		}
+		if used.x > f.x {
+			free = append(free, rect{f.x, f.y, used.x - f.x, f.h})
+		}
+		if used.x+used.w < f.x+f.w {
+			free = append(free, rect{used.x + used.w, f.y,
+				f.x + f.w - used.x - used.w, f.h})
+		}
+		if used.y > f.y {
+			free = append(free, rect{f.x, f.y, f.w, used.y - f.y})
+		}
+		if used.y+used.h < f.y+f.h {
+			free = append(free, rect{f.x, used.y + used.h,
+				f.w, f.y + f.h - used.y - used.h})
+		}
+	}
+
+	// Prune rectangles fully contained by another
+	m.free = nil
+	for i := range free {
+		contained := false
+		for j := range free {
+			if i == j {
+				continue
+			}
+			if free[j].contains(free[i]) &&
+				(free[i] != free[j] || j < i) {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			m.free = append(m.free, free[i])
+		}
+	}
+}
+
+// insert places every size in the bin, always choosing the best
+// scoring rectangle of those remaining. If rotate is set sizes may
+// be turned 90 degrees to fit better. ok is false when the sizes do
+// not fit in the bin.
+func (m *bin) insert(sizes []image.Point, rotate bool) (pos []Pos, rotated []bool, ok bool) {
+	pos = make([]Pos, len(sizes))
+	rotated = make([]bool, len(sizes))
+	done := make([]bool, len(sizes))
+	for n := 0; n < len(sizes); n++ {
+		bi := -1
+		var (
+			best   rect
+			b1, b2 = math.MaxInt32, math.MaxInt32
+			turned bool
+		)
+		for i, sz := range sizes {
+			if done[i] {
+				continue
+			}
+			for _, turn := range []bool{false, true} {
+				if turn && (!rotate || sz.X == sz.Y) {
+					continue
+				}
+				w, h := sz.X, sz.Y
+				if turn {
+					w, h = h, w
+				}
+				r, s1, s2, fits := m.score(w, h)
+				if !fits {
+					continue
+				}
+				if s1 < b1 || (s1 == b1 && s2 < b2) {
+					bi, best, b1, b2, turned = i, r, s1, s2, turn
+				}
+			}
+		}
+		if bi == -1 {
+			return nil, nil, false
+		}
+		m.place(best)
+		done[bi] = true
+		pos[bi] = Pos{best.x, best.y}
+		rotated[bi] = turned
+	}
+	return pos, rotated, true
+}
+
+// MaxRects bin packs images using the MaxRects algorithm. Several
+// sheet widths are tried and the layout with the smallest area wins.
+// Layouts are compared after rounding to PowerOfTwo and Square, and
+// those exceeding MaxWidth or MaxHeight are avoided when possible.
+type MaxRects struct {
+	Heuristic           Heuristic
+	MaxWidth, MaxHeight int
+	PowerOfTwo, Square  bool
+}
+
+// Pack implements Packer
+func (mr MaxRects) Pack(sizes []image.Point, padding int) ([]Pos, Pos, error) {
+	pos, _, dims, err := mr.pack(sizes, padding, false)
+	return pos, dims, err
+}
+
+// PackRotated implements RotatingPacker
+func (mr MaxRects) PackRotated(sizes []image.Point, padding int) ([]Pos, []bool, Pos, error) {
+	return mr.pack(sizes, padding, true)
+}
+
+func (mr MaxRects) pack(sizes []image.Point, padding int, rotate bool) ([]Pos, []bool, Pos, error) {
+	if len(sizes) == 0 {
+		return nil, nil, Pos{}, nil
+	}
+	padded := make([]image.Point, len(sizes))
+	var area, maxW, sumH int
+	for i, sz := range sizes {
+		padded[i] = image.Pt(sz.X+padding, sz.Y+padding)
+		area += padded[i].X * padded[i].Y
+		w, h := padded[i].X, padded[i].Y
+		// Rotated images need only fit by their shorter side
+		if rotate && w > h {
+			w, h = h, w
+		}
+		sumH += h
+		if w > maxW {
+			maxW = w
+		}
+	}
+
+	// A single column is never beaten by a vertical strip
+	widths := []int{maxW}
+	side := math.Sqrt(float64(area))
+	for _, f := range []float64{1, 1.25, 1.5, 2} {
+		widths = append(widths, int(math.Ceil(side*f)))
+	}
+	if mr.MaxWidth > 0 {
+		widths = append(widths, mr.MaxWidth+padding)
+	}
+	if mr.PowerOfTwo {
+		for w := nextPowerOfTwo(maxW - padding); w < int(2*side); w <<= 1 {
+			widths = append(widths, w+padding)
+		}
+	}
+
+	opts := &Options{
+		PowerOfTwo: mr.PowerOfTwo,
+		Square:     mr.Square,
+		MaxWidth:   mr.MaxWidth,
+		MaxHeight:  mr.MaxHeight,
+	}
+	var (
+		best              []Pos
+		bestRotated       []bool
+		bestDims, bestFit Pos
+		bestErr           error
+	)
+	for _, w := range widths {
+		if w < maxW || (mr.MaxWidth > 0 && w > mr.MaxWidth+padding && w != maxW) {
+			continue
+		}
+		m := newBin(w, sumH, mr.Heuristic)
+		pos, rotated, ok := m.insert(padded, rotate)
+		if !ok {
+			continue
+		}
+		// No padding is required on the outside of the sheet
+		var dims Pos
+		for i := range pos {
+			sz := sizes[i]
+			if rotated[i] {
+				sz = transpose(sz)
+			}
+			if x := pos[i].X + sz.X; x > dims.X {
+				dims.X = x
+			}
+			if y := pos[i].Y + sz.Y; y > dims.Y {
+				dims.Y = y
+			}
+		}
+		fit, err := constrain(dims, Pos{}, opts)
+		better := best == nil ||
+			(err == nil && bestErr != nil) ||
+			((err == nil) == (bestErr == nil) &&
+				fit.X*fit.Y < bestFit.X*bestFit.Y)
+		if better {
+			best, bestRotated, bestDims, bestFit, bestErr =
+				pos, rotated, dims, fit, err
+		}
+	}
+	return best, bestRotated, bestDims, nil
+}
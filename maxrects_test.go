@@ -0,0 +1,90 @@
+package spritewell
+
+import (
+	"image"
+	"testing"
+)
+
+func overlaps(sizes []image.Point, pos []Pos, padding int) bool {
+	for i := range pos {
+		a := image.Rect(pos[i].X, pos[i].Y,
+			pos[i].X+sizes[i].X+padding, pos[i].Y+sizes[i].Y+padding)
+		for j := i + 1; j < len(pos); j++ {
+			b := image.Rect(pos[j].X, pos[j].Y,
+				pos[j].X+sizes[j].X+padding, pos[j].Y+sizes[j].Y+padding)
+			if a.Overlaps(b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestPackMaxRects(t *testing.T) {
+	sizes := []image.Point{
+		{64, 64}, {32, 32}, {32, 32}, {16, 48}, {48, 16},
+		{100, 20}, {20, 100}, {8, 8}, {8, 8}, {30, 60},
+	}
+	var area int
+	for _, sz := range sizes {
+		area += sz.X * sz.Y
+	}
+
+	for _, h := range []Heuristic{BestShortSideFit, BestLongSideFit,
+		BestAreaFit, BottomLeft} {
+		for _, padding := range []int{0, 5} {
+			pos, dims, err := MaxRects{Heuristic: h}.Pack(sizes, padding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e := len(sizes); len(pos) != e {
+				t.Fatalf("got: %d wanted: %d", len(pos), e)
+			}
+			if overlaps(sizes, pos, padding) {
+				t.Errorf("heuristic %d padding %d: images overlap", h, padding)
+			}
+			for i := range pos {
+				if pos[i].X+sizes[i].X > dims.X ||
+					pos[i].Y+sizes[i].Y > dims.Y {
+					t.Errorf("image %d outside of sheet %v", i, dims)
+				}
+			}
+			// A vertical strip would be 100 wide and 480 tall
+			if dims.X*dims.Y >= 100*480 {
+				t.Errorf("heuristic %d: poor packing %v", h, dims)
+			}
+		}
+	}
+
+	pos, dims, _ := MaxRects{}.Pack(nil, 0)
+	if pos != nil || dims != (Pos{}) {
+		t.Errorf("got: %v %v wanted empty layout", pos, dims)
+	}
+}
+
+func TestSpriteMaxRects(t *testing.T) {
+	imgs := New(&Options{
+		Pack:      "maxrects",
+		Heuristic: BestAreaFit,
+	})
+	imgs.Decode("test/many/*.jpg")
+
+	bounds := imgs.Dimensions()
+	// Never worse than a vertical strip
+	if bounds.X*bounds.Y > 150*150*5 {
+		t.Errorf("sheet not packed: %v", bounds)
+	}
+
+	for i := 0; i < imgs.Len(); i++ {
+		if p := imgs.GetPack(i); p.X+imgs.ImageWidth(i) > bounds.X ||
+			p.Y+imgs.ImageHeight(i) > bounds.Y {
+			t.Errorf("image %d at %v outside of sheet %v", i, p, bounds)
+		}
+	}
+
+	// Positions are cached until the options change
+	first := imgs.GetPack(1)
+	if p := imgs.GetPack(1); p != first {
+		t.Errorf("got: %v wanted: %v", p, first)
+	}
+}
@@ -0,0 +1,174 @@
+package spritewell
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"image"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownOrder is returned when Options.Order is not one of the
+// supported strategies.
+var ErrUnknownOrder = errors.New("unknown order")
+
+// Strategies for Options.Order. The default keeps the order of the
+// glob matches.
+const (
+	OrderName    = "name"    // by path
+	OrderNatural = "natural" // by path, comparing runs of digits as numbers
+	OrderArea    = "area"    // largest area first
+	OrderHeight  = "height"  // tallest first
+	OrderWidth   = "width"   // widest first
+	OrderMtime   = "mtime"   // oldest modification time first
+)
+
+// naturalLess compares a and b treating runs of digits as numbers, so
+// "icon2" sorts before "icon10".
+func naturalLess(a, b string) bool {
+	for len(a) > 0 && len(b) > 0 {
+		da, db := digits(a), digits(b)
+		if da > 0 && db > 0 {
+			na := strings.TrimLeft(a[:da], "0")
+			nb := strings.TrimLeft(b[:db], "0")
+			if len(na) != len(nb) {
+				return len(na) < len(nb)
+			}
+			if na != nb {
+				return na < nb
+			}
+			a, b = a[da:], b[db:]
+			continue
+		}
+		if a[0] != b[0] {
+			return a[0] < b[0]
+		}
+		a, b = a[1:], b[1:]
+	}
+	return len(a) < len(b)
+}
+
+// digits returns the length of the run of digits s starts with
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// matches reports whether name refers to path the way Lookup does
+func matches(name, path string) bool {
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return name == path || name == base
+}
+
+// sortOrder returns the indices of the images in the order they are
+// packed. Images named in Options.OrderList come first. With
+// Options.Stable images found in prev, the paths of the previous
+// Decode, keep their previous order. The rest are ordered by
+// Options.Order.
+func sortOrder(opts *Options, rels, paths []string, sizes []image.Point, prev []string) ([]int, error) {
+	var less func(a, b int) bool
+	switch opts.Order {
+	case "":
+		less = func(a, b int) bool { return false }
+	case OrderName:
+		less = func(a, b int) bool { return rels[a] < rels[b] }
+	case OrderNatural:
+		less = func(a, b int) bool { return naturalLess(rels[a], rels[b]) }
+	case OrderArea:
+		less = func(a, b int) bool {
+			return sizes[a].X*sizes[a].Y > sizes[b].X*sizes[b].Y
+		}
+	case OrderHeight:
+		less = func(a, b int) bool { return sizes[a].Y > sizes[b].Y }
+	case OrderWidth:
+		less = func(a, b int) bool { return sizes[a].X > sizes[b].X }
+	case OrderMtime:
+		mtimes := make([]int64, len(paths))
+		for i := range paths {
+			fi, err := os.Stat(paths[i])
+			if err != nil {
+				return nil, err
+			}
+			mtimes[i] = fi.ModTime().UnixNano()
+		}
+		less = func(a, b int) bool { return mtimes[a] < mtimes[b] }
+	default:
+		return nil, ErrUnknownOrder
+	}
+
+	// rank images by the explicit list, then the previous order
+	rank := make([]int, len(rels))
+	for i := range rank {
+		rank[i] = len(opts.OrderList) + len(prev)
+		for j, name := range opts.OrderList {
+			if matches(name, rels[i]) {
+				rank[i] = j
+				break
+			}
+		}
+		if rank[i] < len(opts.OrderList) || !opts.Stable {
+			continue
+		}
+		for j, p := range prev {
+			if p == rels[i] {
+				rank[i] = len(opts.OrderList) + j
+				break
+			}
+		}
+	}
+
+	order := make([]int, len(rels))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if rank[a] != rank[b] {
+			return rank[a] < rank[b]
+		}
+		return less(a, b)
+	})
+	return order, nil
+}
+
+// orderPath is the file in GenImgDir keeping the order of the images
+// decoded from patterns, so Options.Stable holds across builds.
+func orderPath(opts *Options, patterns []string) string {
+	sum := md5.Sum([]byte(filepath.ToSlash(opts.ImageDir) + "|" +
+		strings.Join(patterns, "|")))
+	return filepath.Join(opts.GenImgDir,
+		"order-"+hex.EncodeToString(sum[:])[:6]+".json")
+}
+
+// readOrder returns the order saved by writeOrder, nil if there is none
+func readOrder(path string) []string {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var rels []string
+	if err := json.Unmarshal(bs, &rels); err != nil {
+		return nil
+	}
+	return rels
+}
+
+// writeOrder saves the order of rels for readOrder
+func writeOrder(path string, rels []string) error {
+	bs, err := json.Marshal(rels)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, bs, 0644)
+}
@@ -0,0 +1,133 @@
+package spritewell
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestNaturalLess(t *testing.T) {
+	tests := []struct {
+		a, b string
+		less bool
+	}{
+		{"icon2", "icon10", true},
+		{"icon10", "icon2", false},
+		{"icon02", "icon10", true},
+		{"a", "b", true},
+		{"icon", "icon1", true},
+		{"icon1", "icon1", false},
+	}
+	for _, tt := range tests {
+		if got := naturalLess(tt.a, tt.b); got != tt.less {
+			t.Errorf("%s < %s got: %t wanted: %t", tt.a, tt.b, got, tt.less)
+		}
+	}
+}
+
+func TestSortOrder(t *testing.T) {
+	rels := []string{"b10.png", "a.png", "b2.png"}
+	sizes := []image.Point{{10, 10}, {5, 30}, {20, 20}}
+
+	tests := []struct {
+		opts Options
+		prev []string
+		want []int
+	}{
+		{Options{}, nil, []int{0, 1, 2}},
+		{Options{Order: OrderName}, nil, []int{1, 0, 2}},
+		{Options{Order: OrderNatural}, nil, []int{1, 2, 0}},
+		{Options{Order: OrderArea}, nil, []int{2, 1, 0}},
+		{Options{Order: OrderHeight}, nil, []int{1, 2, 0}},
+		{Options{Order: OrderWidth}, nil, []int{2, 0, 1}},
+		{Options{Order: OrderName, OrderList: []string{"b2"}}, nil,
+			[]int{2, 1, 0}},
+		{Options{Order: OrderName, Stable: true},
+			[]string{"b2.png", "b10.png"}, []int{2, 0, 1}},
+	}
+	for i, tt := range tests {
+		order, err := sortOrder(&tt.opts, rels, rels, sizes, tt.prev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range tt.want {
+			if order[j] != tt.want[j] {
+				t.Errorf("%d got: %v wanted: %v", i, order, tt.want)
+				break
+			}
+		}
+	}
+
+	_, err := sortOrder(&Options{Order: "random"}, rels, rels, sizes, nil)
+	if e := ErrUnknownOrder; err != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+}
+
+func TestSpriteOrder(t *testing.T) {
+	imgs := New(&Options{
+		Order:  OrderName,
+		Stable: true,
+	})
+	imgs.Decode("test/many/rss.jpg", "test/many/bird.jpg")
+	if e := 0; imgs.Lookup("bird") != e {
+		t.Errorf("got: %d wanted: %d", imgs.Lookup("bird"), e)
+	}
+
+	// in.jpg sorts first by name, but bird and rss keep their places
+	imgs.Decode("test/many/*.jpg")
+	want := []string{"bird", "rss", "in", "pencil", "twitt"}
+	for i, name := range want {
+		if imgs.Lookup(name) != i {
+			t.Errorf("%s got: %d wanted: %d", name, imgs.Lookup(name), i)
+		}
+	}
+}
+
+func TestSpriteOrderBuilds(t *testing.T) {
+	tmp := setupTemp("TestSpriteOrderBuilds")
+	defer tmp.Close()
+	write := func(name string) {
+		writePNG(t, filepath.Join(tmp.Build, name), 2, 2+len(name), color.Black)
+	}
+	write("b.png")
+	write("c.png")
+	opts := &Options{
+		ImageDir:  tmp.Build,
+		BuildDir:  tmp.Build,
+		GenImgDir: tmp.Image,
+		Order:     OrderName,
+		Stable:    true,
+	}
+	imgs := New(opts)
+	if err := imgs.Decode("*.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := imgs.Export(); err != nil {
+		t.Fatal(err)
+	}
+	if err := imgs.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new build keeps b and c in front of the new a
+	write("a.png")
+	imgs = New(opts)
+	if err := imgs.Decode("*.png"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"b", "c", "a"}
+	for i, name := range want {
+		if imgs.Lookup(name) != i {
+			t.Errorf("%s got: %d wanted: %d", name, imgs.Lookup(name), i)
+		}
+	}
+
+	opts.Stable = false
+	imgs = New(opts)
+	imgs.Decode("*.png")
+	if e := 0; imgs.Lookup("a") != e {
+		t.Errorf("got: %d wanted: %d", imgs.Lookup("a"), e)
+	}
+}
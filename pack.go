@@ -0,0 +1,265 @@
+package spritewell
+
+import (
+	"errors"
+	"image"
+	"sync"
+)
+
+// ErrUnknownPack is returned when Options.Pack names a layout that
+// has not been registered.
+var ErrUnknownPack = errors.New("unknown pack")
+
+// Packer computes the layout of a sprite sheet. Pack receives the
+// size of every image and the padding between them. It returns the
+// Pos of each image and the total dimensions of the sheet.
+type Packer interface {
+	Pack(sizes []image.Point, padding int) ([]Pos, Pos, error)
+}
+
+// RotatingPacker is implemented by Packers that can turn images 90
+// degrees clockwise to pack them more densely. It is used instead of
+// Pack when Options.Rotate is set. rotated reports the images that
+// were turned, their Pos is that of the turned image.
+type RotatingPacker interface {
+	Packer
+	PackRotated(sizes []image.Point, padding int) (pos []Pos, rotated []bool, dims Pos, err error)
+}
+
+// packRotate calls PackRotated if rotate is set and p supports it,
+// Pack otherwise.
+func packRotate(p Packer, sizes []image.Point, padding int, rotate bool) ([]Pos, []bool, Pos, error) {
+	if rp, ok := p.(RotatingPacker); ok && rotate {
+		return rp.PackRotated(sizes, padding)
+	}
+	pos, dims, err := p.Pack(sizes, padding)
+	return pos, make([]bool, len(sizes)), dims, err
+}
+
+// PackerFunc adapts an ordinary function to the Packer interface.
+type PackerFunc func(sizes []image.Point, padding int) ([]Pos, Pos, error)
+
+// Pack calls f(sizes, padding)
+func (f PackerFunc) Pack(sizes []image.Point, padding int) ([]Pos, Pos, error) {
+	return f(sizes, padding)
+}
+
+var (
+	packersMu sync.RWMutex
+	packers   = map[string]func(*Options) Packer{
+		"vert": func(*Options) Packer { return Vertical{} },
+		"horz": func(*Options) Packer { return Horizontal{} },
+		"maxrects": func(opts *Options) Packer {
+			return MaxRects{
+				Heuristic:  opts.Heuristic,
+				MaxWidth:   opts.MaxWidth,
+				MaxHeight:  opts.MaxHeight,
+				PowerOfTwo: opts.PowerOfTwo,
+				Square:     opts.Square,
+			}
+		},
+		"diagonal": func(*Options) Packer { return Diagonal{} },
+		"smart":    func(*Options) Packer { return ZigZag{} },
+		"grid": func(opts *Options) Packer {
+			g := Grid{
+				Columns:    opts.Columns,
+				CellWidth:  opts.CellWidth,
+				CellHeight: opts.CellHeight,
+				Anchor:     opts.Anchor,
+			}
+			// Per side padding lies outside the fixed cell
+			if g.CellWidth != 0 {
+				g.CellWidth += opts.PaddingLeft + opts.PaddingRight
+			}
+			if g.CellHeight != 0 {
+				g.CellHeight += opts.PaddingTop + opts.PaddingBottom
+			}
+			return g
+		},
+	}
+)
+
+// RegisterPacker makes a layout available to Options.Pack by name.
+// fn is called with the options of the Sprite being packed. If
+// RegisterPacker is called twice with the same name, the last
+// registration wins.
+func RegisterPacker(name string, fn func(opts *Options) Packer) {
+	packersMu.Lock()
+	defer packersMu.Unlock()
+	packers[name] = fn
+}
+
+// lookupPacker returns the Packer registered as name. The default
+// pack is vertical.
+func lookupPacker(name string, opts *Options) (Packer, error) {
+	if name == "" {
+		name = "vert"
+	}
+	packersMu.RLock()
+	fn, ok := packers[name]
+	packersMu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownPack
+	}
+	return fn(opts), nil
+}
+
+// Vertical stacks images on top of each other
+type Vertical struct{}
+
+// Pack implements Packer
+func (Vertical) Pack(sizes []image.Point, padding int) ([]Pos, Pos, error) {
+	pos := make([]Pos, len(sizes))
+	var dims Pos
+	for i, sz := range sizes {
+		// No padding on the outside of the image
+		if i > 0 {
+			dims.Y += padding
+		}
+		pos[i] = Pos{0, dims.Y}
+		dims.Y += sz.Y
+		if sz.X > dims.X {
+			dims.X = sz.X
+		}
+	}
+	return pos, dims, nil
+}
+
+// Horizontal lines images up side by side
+type Horizontal struct{}
+
+// Pack implements Packer
+func (Horizontal) Pack(sizes []image.Point, padding int) ([]Pos, Pos, error) {
+	pos := make([]Pos, len(sizes))
+	var dims Pos
+	for i, sz := range sizes {
+		// No padding on the outside of the image
+		if i > 0 {
+			dims.X += padding
+		}
+		pos[i] = Pos{dims.X, 0}
+		dims.X += sz.X
+		if sz.Y > dims.Y {
+			dims.Y = sz.Y
+		}
+	}
+	return pos, dims, nil
+}
+
+// layout is the result of packing the images of a Decode
+type layout struct {
+	pack      string
+	opts      Options
+	positions []Pos
+	repeats   []Repeat
+	rotated   []bool
+	// canon is the index of the first image with identical pixels,
+	// cells the index of the image among those packed
+	canon, cells []int
+	// dims is the size of the first sheet
+	dims      Pos
+	sheets    []int
+	sheetDims []Pos
+	err       error
+}
+
+// packLayout returns the layout of the named pack. Layouts are
+// computed once per Decode and pack, with the options at the time.
+func (l *Sprite) packLayout(pack string) *layout {
+	l.layoutMu.Lock()
+	defer l.layoutMu.Unlock()
+	if lay, ok := l.layouts[pack]; ok {
+		return lay
+	}
+
+	l.optsMu.RLock()
+	opts := *l.opts
+	l.optsMu.RUnlock()
+
+	l.goImagesMu.RLock()
+	sizes := make([]image.Point, len(l.imgs))
+	for i := range l.imgs {
+		sizes[i] = l.imgs[i].Bounds().Size()
+	}
+	canon, unique := dedupe(densityHashes(l.hashes, l.densities))
+	l.goImagesMu.RUnlock()
+
+	l.globMu.RLock()
+	paths, bases := l.paths, l.bases
+	l.globMu.RUnlock()
+
+	lay := &layout{
+		pack:    pack,
+		opts:    opts,
+		repeats: make([]Repeat, len(sizes)),
+		canon:   canon,
+		cells:   make([]int, len(sizes)),
+	}
+	aligns := make([]Align, len(sizes))
+	for i := range lay.repeats {
+		aligns[i] = opts.Align
+		if i >= len(paths) {
+			continue
+		}
+		imgOpts := imageOptions(&opts, paths[i], bases)
+		lay.repeats[i] = imgOpts.Repeat
+		if imgOpts.Align != AlignDefault {
+			aligns[i] = imgOpts.Align
+		}
+	}
+
+	// Identical images are packed once and share a position
+	packed := make([]image.Point, len(unique))
+	repeats := make([]Repeat, len(unique))
+	packedAligns := make([]Align, len(unique))
+	for j, i := range unique {
+		packed[j], repeats[j] = sizes[i], lay.repeats[i]
+		packedAligns[j] = aligns[i]
+		lay.cells[i] = j
+	}
+	for i, c := range canon {
+		lay.cells[i] = lay.cells[c]
+		lay.repeats[i] = lay.repeats[c]
+	}
+
+	p, err := lookupPacker(pack, &opts)
+	var (
+		positions []Pos
+		rotated   []bool
+		sheets    []int
+	)
+	if err == nil {
+		positions, rotated, sheets, lay.sheetDims, err = packSheets(p,
+			packed, repeats, packedAligns, &opts)
+	}
+	if err == nil {
+		lay.dims = lay.sheetDims[0]
+		lay.positions = make([]Pos, len(sizes))
+		lay.rotated = make([]bool, len(sizes))
+		lay.sheets = make([]int, len(sizes))
+		for i, cell := range lay.cells {
+			lay.positions[i] = positions[cell]
+			lay.rotated[i] = rotated[cell]
+			lay.sheets[i] = sheets[cell]
+		}
+	}
+	lay.err = err
+	if l.layouts == nil {
+		l.layouts = make(map[string]*layout)
+	}
+	l.layouts[pack] = lay
+	return lay
+}
+
+// packPos returns the Pos of the image at pos in the named pack. The
+// position one past the last image is the dimensions of the sheet.
+func (l *Sprite) packPos(pack string, pos int) Pos {
+	lay := l.packLayout(pack)
+	if lay.err != nil || pos < 0 || pos > len(lay.positions) {
+		return Pos{0, 0}
+	}
+	if pos == len(lay.positions) {
+		return lay.dims
+	}
+	return lay.positions[pos]
+}
@@ -0,0 +1,78 @@
+package spritewell
+
+import (
+	"image"
+	"testing"
+)
+
+func TestVertical(t *testing.T) {
+	sizes := []image.Point{{96, 139}, {96, 140}}
+	pos, dims, err := Vertical{}.Pack(sizes, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{0, 149}); pos[1] != e {
+		t.Errorf("got: %v wanted: %v", pos[1], e)
+	}
+	if e := (Pos{96, 289}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+}
+
+func TestRegisterPacker(t *testing.T) {
+	// Lay every image out on top of each other
+	RegisterPacker("stack", func(opts *Options) Packer {
+		return PackerFunc(func(sizes []image.Point, padding int) ([]Pos, Pos, error) {
+			var dims Pos
+			for _, sz := range sizes {
+				if sz.X > dims.X {
+					dims.X = sz.X
+				}
+				if sz.Y > dims.Y {
+					dims.Y = sz.Y
+				}
+			}
+			return make([]Pos, len(sizes)), dims, nil
+		})
+	})
+
+	imgs := New(&Options{Pack: "stack"})
+	err := imgs.Decode("test/139.jpg", "test/140.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{96, 140}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+	if e := 0; imgs.Y(1) != e {
+		t.Errorf("got: %d wanted: %d", imgs.Y(1), e)
+	}
+}
+
+func TestUnknownPack(t *testing.T) {
+	imgs := New(&Options{Pack: "spiral"})
+	err := imgs.Decode("test/139.jpg", "test/140.jpg")
+	if e := ErrUnknownPack; err != e {
+		t.Fatalf("got: %v wanted: %s", err, e)
+	}
+}
+
+func TestLayoutCache(t *testing.T) {
+	imgs := New(&Options{})
+	if err := imgs.Decode("test/139.jpg", "test/140.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	lay := imgs.packLayout("vert")
+	imgs.PackHorizontal(1)
+	imgs.PackMaxRects(1)
+	if imgs.packLayout("vert") != lay {
+		t.Error("other packs evicted the vertical layout")
+	}
+
+	if err := imgs.Decode("test/139.jpg", "test/140.jpg"); err != nil {
+		t.Fatal(err)
+	}
+	if imgs.packLayout("vert") == lay {
+		t.Error("Decode did not invalidate the layout")
+	}
+}
@@ -0,0 +1,71 @@
+package spritewell
+
+import (
+	"fmt"
+	"image"
+)
+
+// sidesPadded reports whether per side padding or a margin is set
+func sidesPadded(opts *Options) bool {
+	return opts.PaddingTop != 0 || opts.PaddingRight != 0 ||
+		opts.PaddingBottom != 0 || opts.PaddingLeft != 0 ||
+		opts.Margin != 0
+}
+
+// sidesSeed describes the per side padding and margin for hashing
+func sidesSeed(opts *Options) string {
+	if !sidesPadded(opts) {
+		return ""
+	}
+	return fmt.Sprintf("%d,%d,%d,%d,%d", opts.PaddingTop,
+		opts.PaddingRight, opts.PaddingBottom, opts.PaddingLeft,
+		opts.Margin)
+}
+
+// padSides grows every size by the per side padding. Images are not
+// padded along the axis they repeat on.
+func padSides(sizes []image.Point, repeats []Repeat, opts *Options) []image.Point {
+	padded := make([]image.Point, len(sizes))
+	for i, sz := range sizes {
+		if repeats[i] != RepeatX {
+			sz.X += opts.PaddingLeft + opts.PaddingRight
+		}
+		if repeats[i] != RepeatY {
+			sz.Y += opts.PaddingTop + opts.PaddingBottom
+		}
+		padded[i] = sz
+	}
+	return padded
+}
+
+// unpadSides moves positions of padded sizes onto the image inside
+// the padding and the whole sheet inside the margin. Repeating images
+// are not moved along the axis they repeat on, their bands span the
+// margin too. The sheet stays a multiple of tile along that axis, see
+// repeatTile.
+func unpadSides(pos []Pos, rotated []bool, repeats []Repeat, dims, tile Pos, opts *Options) Pos {
+	for i := range pos {
+		off := Pos{opts.PaddingLeft, opts.PaddingTop}
+		// Turning clockwise moves bottom padding to the left
+		if rotated[i] {
+			off = Pos{opts.PaddingBottom, opts.PaddingLeft}
+		}
+		off.X += opts.Margin
+		off.Y += opts.Margin
+		switch repeats[i] {
+		case RepeatX:
+			off.X = 0
+		case RepeatY:
+			off.Y = 0
+		}
+		pos[i].X += off.X
+		pos[i].Y += off.Y
+	}
+	if dims.X == 0 && dims.Y == 0 {
+		return dims
+	}
+	return Pos{
+		roundUp(dims.X+2*opts.Margin, tile.X),
+		roundUp(dims.Y+2*opts.Margin, tile.Y),
+	}
+}
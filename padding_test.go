@@ -0,0 +1,83 @@
+package spritewell
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestSidesPadding(t *testing.T) {
+	opts := &Options{
+		PaddingTop:    3,
+		PaddingRight:  4,
+		PaddingBottom: 1,
+		PaddingLeft:   2,
+		Margin:        5,
+	}
+	for _, pack := range []string{"vert", "horz", "maxrects", "grid", "diagonal"} {
+		o := *opts
+		o.Pack = pack
+		imgs := New(&o)
+		err := imgs.Decode("test/139.jpg", "test/140.jpg")
+		if err != nil {
+			t.Fatal(err)
+		}
+		bounds := imgs.Dimensions()
+		for i := 0; i < 2; i++ {
+			p := imgs.GetPack(i)
+			if p.X < 7 || p.Y < 8 {
+				t.Errorf("%s: image %d at %v inside padding", pack, i, p)
+			}
+			if p.X+imgs.ImageWidth(i)+4+5 > bounds.X ||
+				p.Y+imgs.ImageHeight(i)+1+5 > bounds.Y {
+				t.Errorf("%s: image %d at %v outside of padding %v",
+					pack, i, p, bounds)
+			}
+		}
+	}
+
+	imgs := New(opts)
+	imgs.Decode("test/139.jpg", "test/140.jpg")
+	if e := (Pos{112, 297}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+	if e := (Pos{7, 151}); imgs.GetPack(1) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(1), e)
+	}
+
+	plain := New(nil)
+	plain.Decode("test/139.jpg", "test/140.jpg")
+	a, _ := imgs.OutputPath()
+	b, _ := plain.OutputPath()
+	if a == b {
+		t.Errorf("padded and plain sheets share path %s", a)
+	}
+}
+
+func TestMarginRepeat(t *testing.T) {
+	tmp := setupTemp("TestMarginRepeat")
+	defer tmp.Close()
+	writePNG(t, filepath.Join(tmp.Build, "block.png"), 5, 5, color.Black)
+	writePNG(t, filepath.Join(tmp.Build, "tile.png"), 3, 1, color.White)
+
+	imgs := New(&Options{
+		ImageDir: tmp.Build,
+		Images: map[string]ImageOptions{
+			"tile": {Repeat: RepeatX},
+		},
+		Margin: 1,
+	})
+	if err := imgs.Decode("*.png"); err != nil {
+		t.Fatal(err)
+	}
+	// The band starts at the left edge and 6+2 is rounded up to 9
+	if e := (Pos{0, 6}); imgs.GetPack(imgs.Lookup("tile")) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(imgs.Lookup("tile")), e)
+	}
+	if e := (Pos{1, 1}); imgs.GetPack(imgs.Lookup("block")) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(imgs.Lookup("block")), e)
+	}
+	if e := (Pos{9, 8}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+}
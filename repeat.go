@@ -0,0 +1,154 @@
+package spritewell
+
+import (
+	"errors"
+	"image"
+)
+
+// ErrRepeatConflict is returned when a sprite contains images that
+// repeat along both axes. A sheet can only tile along one of them.
+var ErrRepeatConflict = errors.New("sprite can not mix repeat-x and repeat-y images")
+
+// ErrRepeatPowerOfTwo is returned when Options.PowerOfTwo is set and
+// a repeating image would not tile a sheet that is a power of two.
+var ErrRepeatPowerOfTwo = errors.New("repeating images must tile a power of two to use PowerOfTwo")
+
+// Repeat marks an image to be tiled along one axis of the sheet
+type Repeat int
+
+const (
+	NoRepeat Repeat = iota
+	// RepeatX images are stretched across the full width of the sheet
+	RepeatX
+	// RepeatY images are stretched across the full height of the sheet
+	RepeatY
+)
+
+// String returns the CSS background-repeat value of r
+func (r Repeat) String() string {
+	switch r {
+	case RepeatX:
+		return "repeat-x"
+	case RepeatY:
+		return "repeat-y"
+	}
+	return "no-repeat"
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func lcm(a, b int) int {
+	if a == 0 || b == 0 {
+		return a + b
+	}
+	return a / gcd(a, b) * b
+}
+
+func transpose(p image.Point) image.Point {
+	return image.Pt(p.Y, p.X)
+}
+
+// repeatTile returns the least common multiple of the widths of the
+// repeat-x images as X and of the heights of the repeat-y images as Y.
+// The sheet must be a multiple of it along that axis.
+func repeatTile(sizes []image.Point, repeats []Repeat) Pos {
+	var tile Pos
+	for i, r := range repeats {
+		switch r {
+		case RepeatX:
+			tile.X = lcm(tile.X, sizes[i].X)
+		case RepeatY:
+			tile.Y = lcm(tile.Y, sizes[i].Y)
+		}
+	}
+	return tile
+}
+
+// packRepeat packs the images that do not repeat with p and then
+// appends a band for every repeating image. Bands of repeat-x images
+// are stacked below the packed images and span the full width of the
+// sheet. The width is a multiple of the least common multiple of
+// their widths, so every band tiles seamlessly. repeat-y bands are
+// placed to the right the same way. Repeating images are never
+// rotated.
+func packRepeat(p Packer, sizes []image.Point, repeats []Repeat, padding int, rotate bool) ([]Pos, []bool, Pos, error) {
+	var (
+		dir  Repeat
+		rest []image.Point
+		idx  []int
+	)
+	for i, r := range repeats {
+		if r == NoRepeat {
+			rest = append(rest, sizes[i])
+			idx = append(idx, i)
+			continue
+		}
+		if dir != NoRepeat && dir != r {
+			return nil, nil, Pos{}, ErrRepeatConflict
+		}
+		dir = r
+	}
+	if dir == NoRepeat {
+		return packRotate(p, sizes, padding, rotate)
+	}
+
+	packed, turned, dims, err := packRotate(p, rest, padding, rotate)
+	if err != nil {
+		return nil, nil, Pos{}, err
+	}
+	pos := make([]Pos, len(sizes))
+	rotated := make([]bool, len(sizes))
+	for j, i := range idx {
+		pos[i], rotated[i] = packed[j], turned[j]
+	}
+
+	// Lay repeat-y bands out as repeat-x on a transposed sheet
+	t := func(p image.Point) image.Point { return p }
+	if dir == RepeatY {
+		t = transpose
+	}
+	d := t(image.Pt(dims.X, dims.Y))
+
+	tile := 0
+	for i, r := range repeats {
+		if r != NoRepeat {
+			tile = lcm(tile, t(sizes[i]).X)
+		}
+	}
+	width := tile
+	if d.X > tile {
+		width = (d.X + tile - 1) / tile * tile
+	}
+	y := d.Y
+	for i, r := range repeats {
+		if r == NoRepeat {
+			continue
+		}
+		if y > 0 {
+			y += padding
+		}
+		o := t(image.Pt(0, y))
+		pos[i] = Pos{o.X, o.Y}
+		y += t(sizes[i]).Y
+	}
+	d = t(image.Pt(width, y))
+	return pos, rotated, Pos{d.X, d.Y}, nil
+}
+
+// Repeat returns how the image at pos is tiled in the sheet. Its
+// String method is the matching CSS background-repeat value.
+func (l *Sprite) Repeat(pos int) Repeat {
+	l.optsMu.RLock()
+	pack := l.opts.Pack
+	l.optsMu.RUnlock()
+	lay := l.packLayout(pack)
+	if pos < 0 || pos >= len(lay.repeats) {
+		return NoRepeat
+	}
+	return lay.repeats[pos]
+}
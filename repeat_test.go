@@ -0,0 +1,90 @@
+package spritewell
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackRepeat(t *testing.T) {
+	sizes := []image.Point{{20, 10}, {4, 2}, {30, 10}, {6, 3}}
+	repeats := []Repeat{NoRepeat, RepeatX, NoRepeat, RepeatX}
+
+	pos, _, dims, err := packRepeat(Vertical{}, sizes, repeats, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// lcm(4, 6) = 12 rounded up to fit the 30px wide image
+	if e := (Pos{36, 28}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+	want := []Pos{{0, 0}, {0, 22}, {0, 11}, {0, 25}}
+	for i := range want {
+		if pos[i] != want[i] {
+			t.Errorf("%d got: %v wanted: %v", i, pos[i], want[i])
+		}
+	}
+
+	repeats = []Repeat{NoRepeat, RepeatY, NoRepeat, RepeatY}
+	_, _, dims, _ = packRepeat(Horizontal{}, sizes, repeats, 0, false)
+	// lcm(2, 3) = 6 rounded up to fit the 10px tall images
+	if e := (Pos{60, 12}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+
+	repeats = []Repeat{NoRepeat, RepeatX, NoRepeat, RepeatY}
+	_, _, _, err = packRepeat(Vertical{}, sizes, repeats, 0, false)
+	if e := ErrRepeatConflict; err != e {
+		t.Errorf("got: %v wanted: %s", err, e)
+	}
+}
+
+func TestSpriteRepeat(t *testing.T) {
+	imgs := New(&Options{
+		Images: map[string]ImageOptions{
+			"pixel": {Repeat: RepeatX},
+		},
+	})
+	err := imgs.Decode("test/139.jpg", "test/140.jpg", "test/pixel.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{96, 280}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+	if e := "repeat-x"; imgs.Repeat(2).String() != e {
+		t.Errorf("got: %s wanted: %s", imgs.Repeat(2), e)
+	}
+	if e := "no-repeat"; imgs.Repeat(0).String() != e {
+		t.Errorf("got: %s wanted: %s", imgs.Repeat(0), e)
+	}
+}
+
+func TestSpriteRepeatConstrain(t *testing.T) {
+	tmp := setupTemp("TestSpriteRepeatConstrain")
+	defer tmp.Close()
+	writePNG(t, filepath.Join(tmp.Build, "block.png"), 5, 5, color.Black)
+	writePNG(t, filepath.Join(tmp.Build, "tile.png"), 3, 1, color.White)
+
+	opts := &Options{
+		ImageDir: tmp.Build,
+		Images: map[string]ImageOptions{
+			"tile": {Repeat: RepeatX},
+		},
+		PowerOfTwo: true,
+	}
+	imgs := New(opts)
+	if err := imgs.Decode("*.png"); err != ErrRepeatPowerOfTwo {
+		t.Errorf("got: %v wanted: %v", err, ErrRepeatPowerOfTwo)
+	}
+
+	opts.PowerOfTwo, opts.Square = false, true
+	imgs = New(opts)
+	if err := imgs.Decode("*.png"); err != nil {
+		t.Fatal(err)
+	}
+	if d := imgs.Dimensions(); d.X%3 != 0 || d.X != d.Y {
+		t.Errorf("got: %v wanted a square multiple of 3 wide", d)
+	}
+}
@@ -0,0 +1,164 @@
+package spritewell
+
+import (
+	"image"
+	"image/draw"
+	"math"
+)
+
+// Filter is the resampling filter used by Options.Downscale
+type Filter int
+
+const (
+	// Lanczos is a windowed sinc with 3 lobes, the sharpest of the
+	// filters. It is the default.
+	Lanczos Filter = iota
+	// Bilinear interpolates linearly between neighbouring pixels
+	Bilinear
+	// Nearest picks the closest pixel, keeping hard edges of pixel art
+	Nearest
+)
+
+// kernel returns the weight function of f and its support in pixels
+func (f Filter) kernel() (func(float64) float64, float64) {
+	if f == Bilinear {
+		return func(x float64) float64 {
+			if x = math.Abs(x); x < 1 {
+				return 1 - x
+			}
+			return 0
+		}, 1
+	}
+	return func(x float64) float64 {
+		x = math.Abs(x)
+		if x == 0 {
+			return 1
+		}
+		if x >= 3 {
+			return 0
+		}
+		px := math.Pi * x
+		return 3 * math.Sin(px) * math.Sin(px/3) / (px * px)
+	}, 3
+}
+
+// resample returns img scaled to w by h pixels with the filter f.
+// Colors are filtered premultiplied by alpha, so transparent pixels
+// never bleed into the edges of an image.
+func resample(img image.Image, w, h int, f Filter) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if w <= 0 || h <= 0 || b.Empty() {
+		return dst
+	}
+	if f == Nearest {
+		for y := 0; y < h; y++ {
+			sy := b.Min.Y + (2*y+1)*b.Dy()/(2*h)
+			for x := 0; x < w; x++ {
+				sx := b.Min.X + (2*x+1)*b.Dx()/(2*w)
+				dst.Set(x, y, img.At(sx, sy))
+			}
+		}
+		return dst
+	}
+
+	sw, sh := b.Dx(), b.Dy()
+	pix := make([]float64, sw*sh*4)
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			i := (y*sw + x) * 4
+			pix[i], pix[i+1] = float64(r), float64(g)
+			pix[i+2], pix[i+3] = float64(bl), float64(a)
+		}
+	}
+	// Scale the rows, then the columns by scaling the rows of the
+	// transposed pixels
+	pix = resampleRows(pix, sw, sh, w, f)
+	pix = resampleRows(transposePix(pix, w, sh), sh, w, h, f)
+	pix = transposePix(pix, h, w)
+
+	for i := 0; i < w*h; i++ {
+		a := clamp(pix[i*4+3], 0xffff)
+		for c := 0; c < 3; c++ {
+			// Premultiplied colors can not exceed alpha
+			dst.Pix[i*4+c] = uint8(clamp(pix[i*4+c], a) / 0x101)
+		}
+		dst.Pix[i*4+3] = uint8(a / 0x101)
+	}
+	return dst
+}
+
+func clamp(v, max float64) float64 {
+	return math.Max(0, math.Min(max, math.Round(v)))
+}
+
+// resampleRows scales every row of pix, w by h pixels of four
+// channels, to dw pixels
+func resampleRows(pix []float64, w, h, dw int, f Filter) []float64 {
+	k, support := f.kernel()
+	scale := float64(w) / float64(dw)
+	// Widen the kernel when shrinking so every source pixel counts
+	fs := math.Max(scale, 1)
+
+	out := make([]float64, dw*h*4)
+	weights := make([]float64, 0, int(2*support*fs)+2)
+	for x := 0; x < dw; x++ {
+		center := (float64(x) + 0.5) * scale
+		lo := int(math.Floor(center - support*fs))
+		hi := int(math.Ceil(center + support*fs))
+		weights = weights[:0]
+		sum := 0.0
+		for i := lo; i <= hi; i++ {
+			wt := k((float64(i) + 0.5 - center) / fs)
+			weights = append(weights, wt)
+			sum += wt
+		}
+		for y := 0; y < h; y++ {
+			row := pix[y*w*4 : (y+1)*w*4]
+			o := (y*dw + x) * 4
+			for j, wt := range weights {
+				if wt == 0 {
+					continue
+				}
+				// Edges are extended
+				i := lo + j
+				if i < 0 {
+					i = 0
+				} else if i >= w {
+					i = w - 1
+				}
+				for c := 0; c < 4; c++ {
+					out[o+c] += row[i*4+c] * wt / sum
+				}
+			}
+		}
+	}
+	return out
+}
+
+// transposePix swaps rows and columns of pix, w by h pixels of four
+// channels
+func transposePix(pix []float64, w, h int) []float64 {
+	out := make([]float64, len(pix))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			copy(out[(x*h+y)*4:(x*h+y)*4+4], pix[(y*w+x)*4:(y*w+x)*4+4])
+		}
+	}
+	return out
+}
+
+// padMultiple grows img with transparent pixels on the right and
+// bottom to a multiple of n
+func padMultiple(img image.Image, n int) image.Image {
+	b := img.Bounds()
+	w := (b.Dx() + n - 1) / n * n
+	h := (b.Dy() + n - 1) / n * n
+	if w == b.Dx() && h == b.Dy() {
+		return img
+	}
+	m := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(m, image.Rect(0, 0, b.Dx(), b.Dy()), img, b.Min, draw.Src)
+	return m
+}
@@ -0,0 +1,97 @@
+package spritewell
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	uniform := image.NewRGBA(image.Rect(0, 0, 5, 3))
+	for i := 0; i < len(uniform.Pix); i += 4 {
+		copy(uniform.Pix[i:], []uint8{255, 0, 0, 255})
+	}
+	for _, f := range []Filter{Lanczos, Bilinear, Nearest} {
+		m := resample(uniform, 2, 2, f)
+		if e := image.Rect(0, 0, 2, 2); m.Bounds() != e {
+			t.Errorf("%d got: %v wanted: %v", f, m.Bounds(), e)
+		}
+		for _, pt := range []image.Point{{0, 0}, {1, 1}} {
+			if c := m.At(pt.X, pt.Y); c != red {
+				t.Errorf("%d got: %v wanted: %v", f, c, red)
+			}
+		}
+	}
+
+	// Quadrants of red and transparent pixels
+	quad := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if (x < 2) == (y < 2) {
+				quad.Set(x, y, red)
+			}
+		}
+	}
+	m := resample(quad, 2, 2, Nearest)
+	if c := m.At(1, 1); c != red {
+		t.Errorf("got: %v wanted: %v", c, red)
+	}
+	if c := m.At(1, 0); c != (color.RGBA{}) {
+		t.Errorf("got: %v wanted: transparent", c)
+	}
+
+	// Transparent pixels never darken the color
+	for _, f := range []Filter{Lanczos, Bilinear} {
+		c := resample(quad, 2, 2, f).RGBAAt(0, 0)
+		if c.A == 0 || c.A == 255 || c.G != 0 || c.R != c.A {
+			t.Errorf("%d got: %v", f, c)
+		}
+	}
+}
+
+func TestSpriteDownscale(t *testing.T) {
+	tmp := setupTemp("TestSpriteDownscale")
+	defer tmp.Close()
+	imgs := New(&Options{
+		BuildDir:  tmp.Build,
+		GenImgDir: tmp.Image,
+		Downscale: 2,
+	})
+	if err := imgs.Decode("test/139.png", "test/140.png"); err != nil {
+		t.Fatal(err)
+	}
+	// 96x139 is padded to 96x140 before it is halved
+	if e := 70; imgs.ImageHeight(0) != e {
+		t.Errorf("got: %d wanted: %d", imgs.ImageHeight(0), e)
+	}
+	if e := (Pos{0, 70}); imgs.GetPack(1) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(1), e)
+	}
+	if ds := imgs.Densities(); len(ds) != 1 || ds[0] != 2 {
+		t.Fatalf("got: %v wanted: [2]", ds)
+	}
+
+	if _, err := imgs.Export(); err != nil {
+		t.Fatal(err)
+	}
+	if err := imgs.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	dpaths, _ := imgs.DensityPaths(2)
+	f, err := os.Open(filepath.Join(tmp.Image, filepath.Base(dpaths[0])))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cfg, err := png.DecodeConfig(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (image.Point{96, 280}); image.Pt(cfg.Width, cfg.Height) != e {
+		t.Errorf("got: %dx%d wanted: %v", cfg.Width, cfg.Height, e)
+	}
+}
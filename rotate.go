@@ -0,0 +1,29 @@
+package spritewell
+
+import "image"
+
+// rotate90 returns a copy of img turned 90 degrees clockwise
+func rotate90(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	m := image.NewRGBA(image.Rect(0, 0, b.Dy(), b.Dx()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			m.Set(b.Max.Y-1-y, x-b.Min.X, img.At(x, y))
+		}
+	}
+	return m
+}
+
+// Rotated reports whether the image at pos was turned 90 degrees
+// clockwise in the sheet. Its footprint in the sheet is then
+// ImageHeight wide and ImageWidth tall. See Options.Rotate
+func (l *Sprite) Rotated(pos int) bool {
+	l.optsMu.RLock()
+	pack := l.opts.Pack
+	l.optsMu.RUnlock()
+	lay := l.packLayout(pack)
+	if lay.err != nil || pos < 0 || pos >= len(lay.rotated) {
+		return false
+	}
+	return lay.rotated[pos]
+}
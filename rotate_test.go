@@ -0,0 +1,70 @@
+package spritewell
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestRotate90(t *testing.T) {
+	m := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	red := color.RGBA{255, 0, 0, 255}
+	m.Set(0, 0, red)
+
+	r := rotate90(m)
+	if e := image.Rect(0, 0, 2, 3); r.Bounds() != e {
+		t.Errorf("got: %v wanted: %v", r.Bounds(), e)
+	}
+	// Top left turns to top right
+	if r.At(1, 0) != red {
+		t.Errorf("got: %v wanted: %v", r.At(1, 0), red)
+	}
+}
+
+func TestPackRotated(t *testing.T) {
+	sizes := []image.Point{{100, 10}, {10, 100}}
+	pos, rotated, dims, err := MaxRects{}.PackRotated(sizes, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Both lie side by side in either orientation
+	if e := 2000; dims.X*dims.Y != e {
+		t.Errorf("got: %v wanted area: %d", dims, e)
+	}
+	if rotated[0] == rotated[1] {
+		t.Errorf("expected exactly one rotated image: %v", rotated)
+	}
+	turned := make([]image.Point, len(sizes))
+	for i := range sizes {
+		turned[i] = sizes[i]
+		if rotated[i] {
+			turned[i] = transpose(sizes[i])
+		}
+	}
+	if overlaps(turned, pos, 0) {
+		t.Errorf("images overlap: %v", pos)
+	}
+
+	_, rotated, _, _ = packRotate(Vertical{}, sizes, 0, true)
+	if rotated[0] || rotated[1] {
+		t.Errorf("Vertical does not rotate: %v", rotated)
+	}
+}
+
+func TestSpriteRotate(t *testing.T) {
+	imgs := New(&Options{
+		Pack:   "maxrects",
+		Rotate: true,
+	})
+	err := imgs.Decode("test/139.jpg", "test/140.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imgs.Rotated(0) || imgs.Rotated(1) {
+		t.Errorf("got: %t %t wanted: false false",
+			imgs.Rotated(0), imgs.Rotated(1))
+	}
+	if e := false; imgs.Rotated(5) != e {
+		t.Errorf("got: %t wanted: %t", imgs.Rotated(5), e)
+	}
+}
@@ -0,0 +1,143 @@
+package spritewell
+
+import (
+	"image"
+	"strconv"
+	"strings"
+)
+
+// packSheets packs sizes with p on a single sheet. If the sheet
+// exceeds the maximum size and Options.Overflow is set, images are
+// split in order across as many sheets as needed. It returns the
+// Pos of every image relative to its sheet, whether it was rotated,
+// the sheet of every image and the dimensions of every sheet.
+func packSheets(p Packer, sizes []image.Point, repeats []Repeat, aligns []Align, opts *Options) ([]Pos, []bool, []int, []Pos, error) {
+	pack := func(idx []int) ([]Pos, []bool, Pos, error) {
+		sz := make([]image.Point, len(idx))
+		rs := make([]Repeat, len(idx))
+		as := make([]Align, len(idx))
+		for j, i := range idx {
+			sz[j], rs[j], as[j] = sizes[i], repeats[i], aligns[i]
+		}
+		padded := padSides(sz, rs, opts)
+		pos, rotated, dims, err := packRepeat(p, padded, rs,
+			opts.Padding, opts.Rotate)
+		if err != nil {
+			return nil, nil, Pos{}, err
+		}
+		align(p, pos, padded, rs, as)
+		tile := repeatTile(padded, rs)
+		dims = unpadSides(pos, rotated, rs, dims, tile, opts)
+		dims, err = constrain(dims, tile, opts)
+		return pos, rotated, dims, err
+	}
+
+	all := make([]int, len(sizes))
+	for i := range all {
+		all[i] = i
+	}
+	positions, rotated, dims, err := pack(all)
+	if _, ok := err.(*SizeError); !ok || !opts.Overflow {
+		return positions, rotated, make([]int, len(sizes)), []Pos{dims}, err
+	}
+
+	positions = make([]Pos, len(sizes))
+	rotated = make([]bool, len(sizes))
+	sheets := make([]int, len(sizes))
+	var (
+		sheetDims   []Pos
+		group       []int
+		last        []Pos
+		lastRotated []bool
+		lastDims    Pos
+	)
+	flush := func() {
+		for j, i := range group {
+			positions[i], rotated[i] = last[j], lastRotated[j]
+			sheets[i] = len(sheetDims)
+		}
+		sheetDims = append(sheetDims, lastDims)
+	}
+	for i := range sizes {
+		pos, turned, dims, err := pack(append(group, i))
+		if _, ok := err.(*SizeError); ok && len(group) > 0 {
+			flush()
+			group = nil
+			pos, turned, dims, err = pack([]int{i})
+		}
+		// An image too large for a sheet of its own
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		group = append(group, i)
+		last, lastRotated, lastDims = pos, turned, dims
+	}
+	flush()
+	return positions, rotated, sheets, sheetDims, nil
+}
+
+// Sheets returns the number of sheets the sprite is split across.
+// This is only ever more than one if Options.Overflow is set.
+func (l *Sprite) Sheets() int {
+	l.optsMu.RLock()
+	pack := l.opts.Pack
+	l.optsMu.RUnlock()
+	lay := l.packLayout(pack)
+	if lay.err != nil || len(lay.sheetDims) == 0 {
+		return 1
+	}
+	return len(lay.sheetDims)
+}
+
+// Sheet returns the sheet the image at pos was packed in. X and Y
+// are relative to this sheet.
+func (l *Sprite) Sheet(pos int) int {
+	l.optsMu.RLock()
+	pack := l.opts.Pack
+	l.optsMu.RUnlock()
+	lay := l.packLayout(pack)
+	if lay.err != nil || pos < 0 || pos >= len(lay.sheets) {
+		return 0
+	}
+	return lay.sheets[pos]
+}
+
+// SheetDimensions is the total W,H pixels of a generated sheet
+func (l *Sprite) SheetDimensions(sheet int) Pos {
+	l.optsMu.RLock()
+	pack := l.opts.Pack
+	l.optsMu.RUnlock()
+	lay := l.packLayout(pack)
+	if lay.err != nil || sheet < 0 || sheet >= len(lay.sheetDims) {
+		return Pos{0, 0}
+	}
+	return lay.sheetDims[sheet]
+}
+
+// SheetPath returns the relative path to the generated sheet the
+// image at pos is packed in.
+func (l *Sprite) SheetPath(pos int) (string, error) {
+	paths, err := l.OutputPaths()
+	if err != nil {
+		return "", err
+	}
+	return paths[l.Sheet(pos)], nil
+}
+
+// OutputPaths returns the relative path of every sheet. The first
+// is always OutputPath, the following are suffixed by their sheet
+// number.
+func (l *Sprite) OutputPaths() ([]string, error) {
+	path, err := l.OutputPath()
+	if err != nil {
+		return nil, err
+	}
+	n := l.Sheets()
+	paths := make([]string, n)
+	paths[0] = path
+	ext := ".png"
+	for i := 1; i < n; i++ {
+		paths[i] = strings.TrimSuffix(path, ext) + "-" + strconv.Itoa(i) + ext
+	}
+	return paths, nil
+}
@@ -0,0 +1,91 @@
+package spritewell
+
+import (
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPackSheets(t *testing.T) {
+	sizes := []image.Point{{10, 10}, {10, 10}, {10, 10}, {10, 10}, {10, 10}}
+	repeats := make([]Repeat, len(sizes))
+	aligns := make([]Align, len(sizes))
+
+	pos, _, sheets, dims, err := packSheets(Vertical{}, sizes, repeats, aligns,
+		&Options{MaxHeight: 25, Overflow: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 3; len(dims) != e {
+		t.Fatalf("got: %d wanted: %d", len(dims), e)
+	}
+	want := []int{0, 0, 1, 1, 2}
+	for i := range want {
+		if sheets[i] != want[i] {
+			t.Errorf("%d got: %d wanted: %d", i, sheets[i], want[i])
+		}
+	}
+	if e := (Pos{0, 10}); pos[3] != e {
+		t.Errorf("got: %v wanted: %v", pos[3], e)
+	}
+	if e := (Pos{10, 10}); dims[2] != e {
+		t.Errorf("got: %v wanted: %v", dims[2], e)
+	}
+
+	_, _, _, _, err = packSheets(Vertical{}, sizes, repeats, aligns,
+		&Options{MaxHeight: 5, Overflow: true})
+	if _, ok := err.(*SizeError); !ok {
+		t.Errorf("got: %v wanted: *SizeError", err)
+	}
+}
+
+func TestSpriteOverflow(t *testing.T) {
+	tmp := setupTemp("TestSpriteOverflow")
+	defer tmp.Close()
+	imgs := New(&Options{
+		GenImgDir: tmp.Image,
+		BuildDir:  tmp.Build,
+		Pack:      "maxrects",
+		MaxWidth:  300,
+		MaxHeight: 300,
+		Overflow:  true,
+	})
+	err := imgs.Decode("test/many/*.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; imgs.Sheets() != e {
+		t.Fatalf("got: %d wanted: %d", imgs.Sheets(), e)
+	}
+	if e := 1; imgs.Sheet(4) != e {
+		t.Errorf("got: %d wanted: %d", imgs.Sheet(4), e)
+	}
+	if e := (Pos{0, 0}); imgs.GetPack(4) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(4), e)
+	}
+
+	paths, err := imgs.OutputPaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 2; len(paths) != e {
+		t.Fatalf("got: %d wanted: %d", len(paths), e)
+	}
+	if p, _ := imgs.SheetPath(4); p != paths[1] {
+		t.Errorf("got: %s wanted: %s", p, paths[1])
+	}
+
+	_, err = imgs.Export()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := imgs.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range paths {
+		if _, err := os.Stat(filepath.Join(tmp.Image, filepath.Base(p))); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package spritewell
+
+import "fmt"
+
+// SizeError is returned when the images of a sprite do not fit within
+// Options.MaxWidth and Options.MaxHeight.
+type SizeError struct {
+	// Width and Height of the sheet required by the layout
+	Width, Height       int
+	MaxWidth, MaxHeight int
+}
+
+func (e *SizeError) Error() string {
+	return fmt.Sprintf("sprite of %dx%d exceeds maximum size %dx%d",
+		e.Width, e.Height, e.MaxWidth, e.MaxHeight)
+}
+
+// nextPowerOfTwo returns the smallest power of two >= n
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// roundUp returns the smallest multiple of m >= n, or n if m is 0
+func roundUp(n, m int) int {
+	if m == 0 {
+		return n
+	}
+	return (n + m - 1) / m * m
+}
+
+// constrain rounds dims up to satisfy PowerOfTwo and Square. Along an
+// axis with repeating images dims stay a multiple of tile, see
+// repeatTile, so the images still tile seamlessly. A *SizeError is
+// returned if the result exceeds MaxWidth or MaxHeight.
+func constrain(dims, tile Pos, opts *Options) (Pos, error) {
+	if dims.X == 0 && dims.Y == 0 {
+		return dims, nil
+	}
+	if opts.PowerOfTwo {
+		// Only powers of two are multiples of a power of two tile
+		if (tile.X != 0 && nextPowerOfTwo(tile.X) != tile.X) ||
+			(tile.Y != 0 && nextPowerOfTwo(tile.Y) != tile.Y) {
+			return dims, ErrRepeatPowerOfTwo
+		}
+		dims.X = nextPowerOfTwo(dims.X)
+		dims.Y = nextPowerOfTwo(dims.Y)
+	}
+	if opts.Square {
+		side := dims.X
+		if dims.Y > side {
+			side = dims.Y
+		}
+		side = roundUp(roundUp(side, tile.X), tile.Y)
+		dims = Pos{side, side}
+	}
+	if (opts.MaxWidth > 0 && dims.X > opts.MaxWidth) ||
+		(opts.MaxHeight > 0 && dims.Y > opts.MaxHeight) {
+		return dims, &SizeError{
+			Width:     dims.X,
+			Height:    dims.Y,
+			MaxWidth:  opts.MaxWidth,
+			MaxHeight: opts.MaxHeight,
+		}
+	}
+	return dims, nil
+}
@@ -0,0 +1,70 @@
+package spritewell
+
+import "testing"
+
+func TestConstrain(t *testing.T) {
+	dims, err := constrain(Pos{96, 279}, Pos{}, &Options{PowerOfTwo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{128, 512}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+
+	dims, _ = constrain(Pos{96, 279}, Pos{}, &Options{Square: true})
+	if e := (Pos{279, 279}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+
+	dims, _ = constrain(Pos{96, 279}, Pos{}, &Options{PowerOfTwo: true, Square: true})
+	if e := (Pos{512, 512}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+
+	_, err = constrain(Pos{96, 279}, Pos{}, &Options{PowerOfTwo: true, MaxHeight: 256})
+	serr, ok := err.(*SizeError)
+	if !ok {
+		t.Fatalf("got: %v wanted: *SizeError", err)
+	}
+	if e := 512; serr.Height != e {
+		t.Errorf("got: %d wanted: %d", serr.Height, e)
+	}
+}
+
+func TestConstrainTile(t *testing.T) {
+	_, err := constrain(Pos{5, 6}, Pos{3, 0}, &Options{PowerOfTwo: true})
+	if err != ErrRepeatPowerOfTwo {
+		t.Errorf("got: %v wanted: %v", err, ErrRepeatPowerOfTwo)
+	}
+	dims, err := constrain(Pos{5, 6}, Pos{4, 0}, &Options{PowerOfTwo: true})
+	if e := (Pos{8, 8}); err != nil || dims != e {
+		t.Errorf("got: %v %v wanted: %v", dims, err, e)
+	}
+	// 8 is the square side, rounded up to a multiple of the tile
+	dims, _ = constrain(Pos{6, 8}, Pos{3, 0}, &Options{Square: true})
+	if e := (Pos{9, 9}); dims != e {
+		t.Errorf("got: %v wanted: %v", dims, e)
+	}
+}
+
+func TestSpriteMaxSize(t *testing.T) {
+	imgs := New(&Options{
+		Pack:       "maxrects",
+		PowerOfTwo: true,
+		MaxWidth:   512,
+		MaxHeight:  512,
+	})
+	err := imgs.Decode("test/many/*.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := (Pos{512, 512}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+
+	imgs = New(&Options{MaxHeight: 512})
+	err = imgs.Decode("test/many/*.jpg")
+	if _, ok := err.(*SizeError); !ok {
+		t.Errorf("got: %v wanted: *SizeError", err)
+	}
+}
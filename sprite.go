@@ -10,13 +10,14 @@ import (
 	"image"
 	"io"
 	"log"
-	"math"
 	mrand "math/rand"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"image/draw"
 	_ "image/gif"
@@ -38,6 +39,14 @@ type Sprite struct {
 	goImagesMu sync.RWMutex
 	len        int
 	imgs       []image.Image
+	// sources and offsets record the size and trim offset of the
+	// images before they were trimmed
+	sources []image.Point
+	offsets []Pos
+	// hashes of the pixels of every image, see Duplicates
+	hashes []string
+	// densities holds high density variants, see Densities
+	densities []density
 
 	outFileMu sync.RWMutex
 	outFile   string
@@ -45,8 +54,30 @@ type Sprite struct {
 	combineMu sync.Mutex
 	Combined  bool
 
+	// statistics of the last Decode, see Stats
+	statsMu                sync.Mutex
+	encodedSize            int
+	decodeTime, encodeTime time.Duration
+
+	// manifest describes the last Decode, it is written by Export.
+	// fresh is set when it matched the manifest on disk.
+	manifestMu sync.Mutex
+	manifest   []byte
+	fresh      bool
+
+	// layouts caches the positions of each pack
+	layoutMu sync.Mutex
+	layouts  map[string]*layout
+
 	globMu       sync.RWMutex
 	globs, paths []string
+	// queries holds the directives of every image in paths, bases
+	// the rel every image with directives or of a color variant was
+	// derived from
+	queries []url.Values
+	bases   map[string]string
+	// patterns passed to the last Decode
+	patterns []string
 
 	// Channels to do work
 	queue    chan work
@@ -60,6 +91,102 @@ type Options struct {
 	BuildDir, ImageDir, GenImgDir string
 	Pack                          string
 	Padding                       int // Padding in pixels
+	// Padding in pixels added to each side of every image, including
+	// those on the outside of the sheet
+	PaddingTop, PaddingRight, PaddingBottom, PaddingLeft int
+	// Margin in pixels around the whole sheet
+	Margin int
+	// Align positions images across vertical and horizontal sheets
+	Align Align
+	// Order images before packing, see OrderName. Images matching
+	// the names in OrderList come first, in the order listed.
+	Order     string
+	OrderList []string
+	// Stable keeps images known from the previous Decode in their
+	// previous order, so positions change as little as possible. The
+	// order is saved in GenImgDir by Export, so it holds for a new
+	// Sprite decoding the same patterns.
+	Stable bool
+	// ContentHash includes the decoded pixels in OutputPath
+	ContentHash bool
+	// Heuristic used to place images when Pack is "maxrects"
+	Heuristic Heuristic
+	// Columns and cell size used when Pack is "grid", see Grid. The
+	// per side padding is added around the cell size.
+	Columns               int
+	CellWidth, CellHeight int
+	Anchor                Anchor
+	// Images configures individual images by the names Lookup accepts
+	Images map[string]ImageOptions
+	// Filters change the colors of every image
+	Filters []ColorFilter
+	// Variants adds a copy of every image with the filters applied
+	// after Filters. Copies are named after the image suffixed by the
+	// key, ie. Variants["disabled"] of arrow.png is arrow-disabled.png
+	Variants map[string][]ColorFilter
+	// PowerOfTwo and Square round the dimensions of the sheet up
+	PowerOfTwo, Square bool
+	// MaxWidth and MaxHeight limit the size of the sheet, 0 is
+	// unlimited. Decode fails with a *SizeError if they are exceeded.
+	MaxWidth, MaxHeight int
+	// Overflow splits the images across several sheets instead of
+	// failing when MaxWidth or MaxHeight are exceeded. See Sheet.
+	Overflow bool
+	// Trim removes fully transparent rows and columns from the edges
+	// of every image before packing. See TrimOffset
+	Trim bool
+	// Rotate allows packs implementing RotatingPacker to turn images
+	// 90 degrees clockwise. See Rotated
+	Rotate bool
+	// Manifest writes the paths, sizes and pixel hashes of the images
+	// with the options next to the sheets. Decode and Export skip
+	// encoding while they match the sheets on disk.
+	Manifest bool
+	// Downscale treats every source as an image of this density, ie.
+	// 2 for @2x artwork. They are resampled with Filter to derive the
+	// 1x sheet, while the sources are drawn in the sheet of their
+	// density. Sources are padded to a multiple of Downscale, so both
+	// sheets line up exactly. See Densities
+	Downscale int
+	Filter    Filter
+	// Extrude repeats the border pixels of every image this many
+	// pixels into the padding around it. This prevents transparent
+	// bleeding when sheets are sampled with bilinear filtering.
+	// Padding should be at least twice Extrude.
+	Extrude int
+}
+
+// ImageOptions configures an individual image of a Sprite. They are
+// set in Options.Images keyed by the same names Lookup accepts.
+type ImageOptions struct {
+	Repeat Repeat
+	// Align overrides Options.Align for the image
+	Align Align
+	// Transform changes the image before it is packed. Directives
+	// passed to Decode override it, see Decode
+	Transform Transform
+	// Filters change the colors of the image, before Options.Filters
+	Filters []ColorFilter
+}
+
+// imageOptions returns the ImageOptions of path. Images without
+// options of their own are configured like the image in bases they
+// were derived from.
+func imageOptions(opts *Options, path string, bases map[string]string) ImageOptions {
+	for {
+		if o, ok := opts.Images[path]; ok {
+			return o
+		}
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if o, ok := opts.Images[base]; ok {
+			return o
+		}
+		orig, ok := bases[path]
+		if !ok {
+			return ImageOptions{}
+		}
+		path = orig
+	}
 }
 
 func New(opts *Options) *Sprite {
@@ -81,11 +208,21 @@ type work struct {
 	imgs []image.Image
 	pos  Pos
 	pack string
+	// positions of each image in imgs
+	positions []Pos
+	repeats   []Repeat
+	rotated   []bool
+	canon     []int
+	sheets    []int
+	sheetDims []Pos
+	extrude   int
+	densities []density
 }
 
 type result struct {
-	buf *bytes.Buffer
-	err error
+	// bufs holds the encoded image of every sheet
+	bufs []*bytes.Buffer
+	err  error
 }
 
 // SafeImageMap provides a thread-safe data structure for
@@ -162,10 +299,18 @@ func (l *Sprite) Lookup(f string) int {
 	var base string
 	pos := -1
 	l.globMu.RLock()
-	paths := l.paths
+	paths, queries, bases := l.paths, l.queries, l.bases
 	l.globMu.RUnlock()
 
+	// A pattern with directives finds the image decoded from it
+	f, query := cutDirectives(f)
 	for i, v := range paths {
+		if query != nil {
+			if i >= len(queries) || queries[i].Encode() != query.Encode() {
+				continue
+			}
+			v = bases[v]
+		}
 		base = filepath.Base(v)
 		base = strings.TrimSuffix(base, filepath.Ext(v))
 		if f == v {
@@ -191,7 +336,8 @@ func (l *Sprite) Lookup(f string) int {
 
 // Return the X position of an image based
 // on the layout (vertical/horizontal) and
-// position in Image slice
+// position in Image slice. If the sprite overflows onto several
+// sheets, the position is relative to Sheet(pos).
 func (l *Sprite) X(pos int) int {
 	p := l.GetPack(pos)
 	return p.X
@@ -199,7 +345,8 @@ func (l *Sprite) X(pos int) int {
 
 // Return the Y position of an image based
 // on the layout (vertical/horizontal) and
-// position in Image slice
+// position in Image slice. If the sprite overflows onto several
+// sheets, the position is relative to Sheet(pos).
 func (l *Sprite) Y(pos int) int {
 	p := l.GetPack(pos)
 	return p.Y
@@ -255,7 +402,10 @@ func (l *Sprite) String() string {
 
 // OutputPath generates a unique filename based on the relative path
 // from image directory to build directory and the files matched in
-// the glob lookup.  OutputPath is not cache safe.
+// the glob lookup.  OutputPath is not cache safe, unless
+// Options.ContentHash is set. Then the pixels of every image and the
+// layout are hashed as well and any change to them produces a new
+// filename.
 func (l *Sprite) OutputPath() (string, error) {
 	l.outFileMu.RLock()
 	outFile := l.outFile
@@ -266,7 +416,7 @@ func (l *Sprite) OutputPath() (string, error) {
 	}
 
 	l.globMu.RLock()
-	globs := l.globs
+	globs, queries := l.globs, l.queries
 	l.globMu.RUnlock()
 	if len(globs) == 0 {
 		return "", ErrNoPattern
@@ -276,6 +426,8 @@ func (l *Sprite) OutputPath() (string, error) {
 	path, err := filepath.Rel(l.opts.BuildDir, l.opts.GenImgDir)
 	pack := l.opts.Pack
 	padding := l.opts.Padding
+	sides := sidesSeed(l.opts)
+	contentHash := l.opts.ContentHash
 	l.optsMu.RUnlock()
 	if err != nil {
 		return "", err
@@ -296,9 +448,33 @@ func (l *Sprite) OutputPath() (string, error) {
 		}
 
 	}
+	// Directives change the images decoded from the globs
+	for i, query := range queries {
+		if len(query) > 0 {
+			relglobs[i] += "?" + query.Encode()
+		}
+	}
 	hasher := md5.New()
 	seed := pack + strconv.Itoa(padding) + "|" +
 		filepath.ToSlash(path+"|"+strings.Join(relglobs, "|"))
+	// Only hashed when set, so existing sheets keep their names
+	if len(sides) > 0 {
+		seed += "|" + sides
+	}
+	if contentHash {
+		l.goImagesMu.RLock()
+		seed += "|" + strings.Join(l.hashes, "|")
+		// Variants change the sheets of their density
+		for _, d := range l.densities {
+			seed += fmt.Sprintf("|%d|%s", d.scale,
+				strings.Join(d.hashes, "|"))
+		}
+		l.goImagesMu.RUnlock()
+		// The layout covers every option moving the images
+		lay := l.packLayout(pack)
+		seed += fmt.Sprint("|", lay.positions, lay.rotated, lay.repeats,
+			lay.sheets, lay.sheetDims, lay.opts.Extrude)
+	}
 	hasher.Write([]byte(seed))
 	salt := hex.EncodeToString(hasher.Sum(nil))[:6]
 	outFile = filepath.Join(path, salt+".png")
@@ -311,20 +487,39 @@ func (l *Sprite) OutputPath() (string, error) {
 
 // Decode accepts a variable number of glob patterns.  The ImageDir
 // is assumed to be prefixed to the globs provided.
+//
+// A pattern may end in transform directives applied to every image it
+// matches, ie. "arrow.png?scale=0.5&rotate=90&flip=h". See Transform.
+// The images are named after the path and the directives, ie.
+// "arrow.png?flip=h" is found by Lookup("arrow-flip-h") as well as by
+// the pattern itself, unless a name directive replaces the base name:
+// "arrow.png?flip=h&name=back" is found by Lookup("back"). A variant
+// directive applies the filters of Options.Variants, ie.
+// "arrow.png?variant=disabled".
 func (l *Sprite) Decode(rest ...string) error {
+	start := time.Now()
 
 	// Invalidate the composite cache
 	var (
 		paths []string
 		rels  []string
+		// directives and originals of images by rel
+		queries = make(map[string]url.Values)
+		bases   = make(map[string]string)
 	)
 
 	l.optsMu.RLock()
 	absImageDir, _ := filepath.Abs(l.opts.ImageDir)
 	relImageDir := l.opts.ImageDir
+	trimmed := l.opts.Trim
+	downscale, filter := l.opts.Downscale, l.opts.Filter
+	images := l.opts.Images
+	filters, colorVariants := l.opts.Filters, l.opts.Variants
+	stable, orderFile := l.opts.Stable, orderPath(l.opts, rest)
 	l.optsMu.RUnlock()
 
 	for _, r := range rest {
+		r, query := cutDirectives(r)
 		matches, err := filepath.Glob(filepath.Join(relImageDir, r))
 		if err != nil {
 			panic(err)
@@ -345,6 +540,12 @@ func (l *Sprite) Decode(rest ...string) error {
 			} else if p, err := filepath.Rel(absImageDir, matches[i]); err == nil {
 				rel[i] = p
 			}
+			// Directives are kept by rel to apply them on decode
+			if len(query) > 0 {
+				name := directiveRel(rel[i], query)
+				queries[name], bases[name] = query, rel[i]
+				rel[i] = name
+			}
 		}
 		rels = append(rels, rel...)
 		paths = append(paths, matches...)
@@ -354,39 +555,190 @@ func (l *Sprite) Decode(rest ...string) error {
 	if len(rels) == 0 {
 		return ErrNoImages
 	}
+	rels, paths, variants := splitDensities(rels, paths)
+	rels, paths = addColorVariants(colorVariants, rels, paths, variants,
+		queries, bases)
+	directives := func(rels []string) []url.Values {
+		qs := make([]url.Values, len(rels))
+		for i, rel := range rels {
+			qs[i] = queries[rel]
+		}
+		return qs
+	}
 
 	l.globMu.Lock()
+	prev := l.paths
 	l.paths = rels
 	l.globs = paths
+	l.queries = directives(rels)
+	l.bases = bases
+	l.patterns = rest
 	l.globMu.Unlock()
+	// Without a previous Decode, continue the order of the last build
+	if prev == nil && stable {
+		prev = readOrder(orderFile)
+	}
 
 	imgs := make([]image.Image, 0, len(paths))
-	for _, path := range paths {
-		f, err := os.Open(path)
+	sources := make([]image.Point, 0, len(paths))
+	offsets := make([]Pos, 0, len(paths))
+	hashes := make([]string, 0, len(paths))
+	// load decodes the image at path, a variant at density n of rel,
+	// and applies its Transform
+	load := func(path, rel string, n int) (image.Image, error) {
+		img, err := decodeImage(path)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		img, _, err := image.Decode(f)
+		query := queries[rel]
+		imgOpts := imageOptions(&Options{Images: images}, rel, bases)
+		t := imgOpts.Transform
+		if err := t.parse(query); err != nil {
+			return nil, err
+		}
+		img = t.apply(img, n, filter)
+		fs := append(append(append([]ColorFilter{}, imgOpts.Filters...),
+			filters...), colorVariants[query.Get("variant")]...)
+		return applyFilters(img, fs)
+	}
+	// highs are the sources of downscaled images
+	highs := make([]image.Image, len(paths))
+	for i, path := range paths {
+		img, err := load(path, rels[i], 1)
 		if err != nil {
-			ext := filepath.Ext(path)
-			if !CanDecode(ext) {
-				return fmt.Errorf("format: %s not supported", ext)
-			} else {
-				return fmt.Errorf("Error processing: %s\n%s", path, err)
-			}
+			return err
+		}
+		if downscale > 1 {
+			highs[i] = padMultiple(img, downscale)
+			b := highs[i].Bounds()
+			img = resample(highs[i], b.Dx()/downscale,
+				b.Dy()/downscale, filter)
+		}
+		sources = append(sources, img.Bounds().Size())
+		var offset Pos
+		if trimmed {
+			img, offset = trim(img)
 		}
 		imgs = append(imgs, img)
+		offsets = append(offsets, offset)
+		hashes = append(hashes, pixelHash(img))
+	}
+
+	sizes := make([]image.Point, len(imgs))
+	for i := range imgs {
+		sizes[i] = imgs[i].Bounds().Size()
+	}
+	l.optsMu.RLock()
+	order, err := sortOrder(l.opts, rels, paths, sizes, prev)
+	l.optsMu.RUnlock()
+	if err != nil {
+		return err
+	}
+	sorted := struct {
+		rels, paths, hashes []string
+		imgs, highs         []image.Image
+		sources             []image.Point
+		offsets             []Pos
+	}{
+		make([]string, len(order)), make([]string, len(order)),
+		make([]string, len(order)), make([]image.Image, len(order)),
+		make([]image.Image, len(order)),
+		make([]image.Point, len(order)), make([]Pos, len(order)),
+	}
+	for i, j := range order {
+		sorted.rels[i], sorted.paths[i] = rels[j], paths[j]
+		sorted.hashes[i], sorted.imgs[i] = hashes[j], imgs[j]
+		sorted.highs[i] = highs[j]
+		sorted.sources[i], sorted.offsets[i] = sources[j], offsets[j]
 	}
+	rels, paths, hashes = sorted.rels, sorted.paths, sorted.hashes
+	imgs, highs = sorted.imgs, sorted.highs
+	sources, offsets = sorted.sources, sorted.offsets
+
+	l.globMu.Lock()
+	l.paths = rels
+	l.globs = paths
+	l.queries = directives(rels)
+	l.globMu.Unlock()
+
+	densities, err := decodeDensities(load, variants, rels, imgs, sources, offsets)
+	if err != nil {
+		return err
+	}
+	if downscale > 1 {
+		densities = deriveDensity(densities, downscale, highs, imgs, offsets)
+	}
+
+	// The output path depends on the globs and pixels just decoded
+	l.outFileMu.Lock()
+	l.outFile = ""
+	l.outFileMu.Unlock()
+
+	l.statsMu.Lock()
+	l.decodeTime = time.Since(start)
+	l.encodedSize = 0
+	l.statsMu.Unlock()
 
 	l.goImagesMu.Lock()
 	l.imgs = imgs
+	l.sources = sources
+	l.offsets = offsets
+	l.hashes = hashes
+	l.densities = densities
 	l.len = len(imgs)
 	l.goImagesMu.Unlock()
 
-	l.queue <- work{pos: l.Dimensions(), imgs: imgs}
+	// Invalidate the layout, positions are computed once per Decode
+	l.layoutMu.Lock()
+	l.layouts = nil
+	l.layoutMu.Unlock()
+
+	l.optsMu.RLock()
+	pack := l.opts.Pack
+	l.optsMu.RUnlock()
+	lay := l.packLayout(pack)
+	if lay.err != nil {
+		return lay.err
+	}
+
+	// Nothing to encode when the sheets on disk are up to date
+	if fresh, err := l.checkManifest(); err != nil || fresh {
+		return err
+	}
+
+	l.queue <- work{
+		pos:       lay.dims,
+		imgs:      imgs,
+		positions: lay.positions,
+		repeats:   lay.repeats,
+		rotated:   lay.rotated,
+		canon:     lay.canon,
+		sheets:    lay.sheets,
+		sheetDims: lay.sheetDims,
+		extrude:   lay.opts.Extrude,
+		densities: densities,
+	}
 	return nil
 }
 
+// decodeImage opens and decodes the image at path
+func decodeImage(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := image.Decode(f)
+	if err != nil {
+		ext := filepath.Ext(path)
+		if !CanDecode(ext) {
+			return nil, fmt.Errorf("format: %s not supported", ext)
+		}
+		return nil, fmt.Errorf("Error processing: %s\n%s", path, err)
+	}
+	return img, nil
+}
+
 // CanDecode checks if the file extension is supported by
 // spritewell.
 func CanDecode(ext string) bool {
@@ -399,34 +751,112 @@ func CanDecode(ext string) bool {
 }
 
 func (l *Sprite) loopAndCombine(queue chan work, resp chan result) {
+	var (
+		// pending is nil until a result is ready to be sent, so a
+		// later Decode replaces a result nobody exported
+		pending chan result
+		res     result
+	)
 	for {
 		select {
 		case work := <-queue:
-			imgs := work.imgs
-			pos := work.pos
-			maxW, maxH := pos.X, pos.Y
+			start := time.Now()
 			l.combineMu.Lock()
-			defer l.combineMu.Unlock()
-			goimg := image.NewRGBA(image.Rect(0, 0, maxW, maxH))
+			sheets := work.combine()
+			// Denser sheets follow in the order of Densities
+			for _, d := range work.densities {
+				sheets = append(sheets,
+					work.scale(d.scale, d.imgs).combine()...)
+			}
+			l.combineMu.Unlock()
+
+			bufs := make([]*bytes.Buffer, len(sheets))
+			var err error
+			size := 0
+			for i := range sheets {
+				bufs[i] = new(bytes.Buffer)
+				// Set the buf so bytes.Buffer works
+				err = png.Encode(bufs[i], sheets[i])
+				if err != nil {
+					log.Fatal(err)
+				}
+				size += bufs[i].Len()
+			}
+			l.statsMu.Lock()
+			l.encodedSize = size
+			l.encodeTime = time.Since(start)
+			l.statsMu.Unlock()
+			res = result{bufs: bufs, err: err}
+			pending = resp
+		case pending <- res:
+			pending = nil
+		}
+	}
+}
 
-			for i := 0; i < len(imgs); i++ {
-				pos := l.GetPack(i)
-				draw.Draw(goimg, goimg.Bounds(), imgs[i],
-					image.Point{
-						X: -pos.X,
-						Y: -pos.Y,
-					}, draw.Src)
+// combine draws every image of the work onto its sheet
+func (w work) combine() []*image.RGBA {
+	sheets := make([]*image.RGBA, len(w.sheetDims))
+	for i, dims := range w.sheetDims {
+		sheets[i] = image.NewRGBA(image.Rect(0, 0, dims.X, dims.Y))
+	}
+
+	imgs := make([]image.Image, len(w.imgs))
+	for i := range w.imgs {
+		// Duplicates share the position of the first image
+		if w.canon[i] != i {
+			continue
+		}
+		imgs[i] = w.imgs[i]
+		if w.rotated[i] {
+			imgs[i] = rotate90(imgs[i])
+		}
+	}
+
+	// Extrusions are drawn first so they never cover an image
+	if w.extrude > 0 {
+		for i, img := range imgs {
+			if img == nil || w.repeats[i] != NoRepeat {
+				continue
 			}
+			pos := w.positions[i]
+			extrude(sheets[w.sheets[i]], img,
+				image.Pt(pos.X, pos.Y), w.extrude)
+		}
+	}
 
-			buf := new(bytes.Buffer)
-			// Set the buf so bytes.Buffer works
-			err := png.Encode(buf, goimg)
-			if err != nil {
-				log.Fatal(err)
+	for i, img := range imgs {
+		if img == nil {
+			continue
+		}
+		goimg := sheets[w.sheets[i]]
+		maxW, maxH := goimg.Bounds().Dx(), goimg.Bounds().Dy()
+		pos := w.positions[i]
+		size := img.Bounds().Size()
+		if size.X == 0 || size.Y == 0 {
+			continue
+		}
+		// Repeating images are tiled across the sheet
+		step := image.Point{maxW, maxH}
+		switch w.repeats[i] {
+		case RepeatX:
+			step.X = size.X
+		case RepeatY:
+			step.Y = size.Y
+		}
+		for x := pos.X; x < maxW; x += step.X {
+			for y := pos.Y; y < maxH; y += step.Y {
+				// Trimmed images do not start at 0,0
+				origin := img.Bounds().Min
+				draw.Draw(goimg, goimg.Bounds(), img,
+					image.Point{
+						X: origin.X - x,
+						Y: origin.Y - y,
+					}, draw.Src)
 			}
-			resp <- result{buf: buf, err: err}
 		}
 	}
+	return sheets
 }
 
 // Pos represents the x, y coordinates of an image
@@ -436,85 +866,33 @@ type Pos struct {
 }
 
 // GetPack retrieves the Pos of an image in the
-// list of images.
+// list of images. The Packer registered as Options.Pack
+// determines the layout, default is vertical.
 // TODO: Changing l.Pack will update the positions, but
 // the sprite file will need to be regenerated via Decode.
 func (l *Sprite) GetPack(pos int) Pos {
 	l.optsMu.RLock()
 	pack := l.opts.Pack
 	l.optsMu.RUnlock()
-	// Default is vertical
-	if pack == "vert" {
-		return l.PackVertical(pos)
-	} else if pack == "horz" {
-		return l.PackHorizontal(pos)
-	}
-	return l.PackVertical(pos)
+	return l.packPos(pack, pos)
 }
 
-// PackVertical finds the Pos for a vertically packed sprite
+// PackVertical finds the Pos for a vertically packed sprite. Images
+// are offset horizontally by their Align.
 func (l *Sprite) PackVertical(pos int) Pos {
-	if pos == -1 || pos == 0 {
-		return Pos{0, 0}
-	}
-	var x, y int
-	var rect image.Rectangle
-	l.optsMu.RLock()
-	padding := l.opts.Padding
-	l.optsMu.RUnlock()
-	// there are n-1 paddings in an image list
-	y = padding * (pos)
-	// No padding on the outside of the image
-	numimages := l.Len()
-	if pos == numimages {
-		y -= padding
-	}
-	l.goImagesMu.RLock()
-	for i := 1; i <= pos; i++ {
-		rect = l.imgs[i-1].Bounds()
-		y += rect.Dy()
-		if pos == numimages {
-			x = int(math.Max(float64(x), float64(rect.Dx())))
-		}
-	}
-	l.goImagesMu.RUnlock()
-
-	return Pos{
-		x, y,
-	}
+	return l.packPos("vert", pos)
 }
 
-// PackHorzontal finds the Pos for a horizontally packed sprite
+// PackHorzontal finds the Pos for a horizontally packed sprite.
+// Images are offset vertically by their Align.
 func (l *Sprite) PackHorizontal(pos int) Pos {
-	if pos == -1 || pos == 0 {
-		return Pos{0, 0}
-	}
-	var x, y int
-	var rect image.Rectangle
-	l.optsMu.RLock()
-	padding := l.opts.Padding
-	l.optsMu.RUnlock()
-
-	// there are n-1 paddings in an image list
-	x = padding * pos
-	// No padding on the outside of the image
-	numimages := l.Len()
-	if pos == numimages {
-		x -= padding
-	}
-	for i := 1; i <= pos; i++ {
-		l.goImagesMu.RLock()
-		rect = l.imgs[i-1].Bounds()
-		l.goImagesMu.RUnlock()
-		x += rect.Dx()
-		if pos == numimages {
-			y = int(math.Max(float64(y), float64(rect.Dy())))
-		}
-	}
+	return l.packPos("horz", pos)
+}
 
-	return Pos{
-		x, y,
-	}
+// PackMaxRects finds the Pos for a sprite bin packed with the
+// MaxRects algorithm.
+func (l *Sprite) PackMaxRects(pos int) Pos {
+	return l.packPos("maxrects", pos)
 }
 
 func randString(n int) string {
@@ -527,60 +905,151 @@ func randString(n int) string {
 	return string(bytes)
 }
 
-func (l *Sprite) export() (*os.File, string, error) {
+// sheetFiles returns the absolute path of every sheet in GenImgDir,
+// followed by the sheets of every density
+func (l *Sprite) sheetFiles() ([]string, error) {
 	// Use the auto generated path if none is specified
 	// TODO: Differentiate relative file path (in css) to this abs one
-	opath, err := l.OutputPath()
+	opaths, err := l.OutputPaths()
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
-	l.optsMu.RLock()
-	abs, err := filepath.Abs(filepath.Join(l.opts.GenImgDir,
-		filepath.Base(opath)))
-	l.optsMu.RUnlock()
+	for _, n := range l.Densities() {
+		dpaths, err := l.DensityPaths(n)
+		if err != nil {
+			return nil, err
+		}
+		opaths = append(opaths, dpaths...)
+	}
+	abss := make([]string, len(opaths))
+	for i, opath := range opaths {
+		l.optsMu.RLock()
+		abss[i], err = filepath.Abs(filepath.Join(l.opts.GenImgDir,
+			filepath.Base(opath)))
+		l.optsMu.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return abss, nil
+}
 
-	err = os.MkdirAll(filepath.Dir(abs), 0755)
+func (l *Sprite) export() ([]*os.File, string, error) {
+	abss, err := l.sheetFiles()
 	if err != nil {
 		return nil, "", err
 	}
+	files := make([]*os.File, len(abss))
+	first := abss[0]
+	// A manifest must never outlive the sheets it describes
+	err = os.Remove(manifestPath(first))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, "", err
+	}
+	for i, abs := range abss {
+		err = os.MkdirAll(filepath.Dir(abs), 0755)
+		if err != nil {
+			closeFiles(files)
+			return nil, "", err
+		}
 
-	fo, err := os.Create(abs)
-	if err != nil {
-		if _, err := os.Stat(abs); err == nil {
-			return nil, abs, nil
+		fo, err := os.Create(abs)
+		if err != nil {
+			closeFiles(files)
+			if _, err := os.Stat(abs); err == nil {
+				return nil, first, nil
+			}
+			return nil, "", err
 		}
-		return nil, "", err
+		files[i] = fo
 	}
-	return fo, abs, err
+	return files, first, nil
 }
 
 // Export returns the output path of the combined sprite and flushes
 // the sprite to disk. This method does not block on disk I/O. See Wait
+//
+// When the sprite overflows onto several sheets, every sheet is
+// written and the path of the first is returned.
+//
+// With Options.Manifest nothing is written when the sheets on disk are
+// up to date.
 func (s *Sprite) Export() (abs string, err error) {
-	of, abs, err := s.export()
+	s.manifestMu.Lock()
+	fresh, manifest := s.fresh, s.manifest
+	s.manifestMu.Unlock()
+	if fresh {
+		abss, err := s.sheetFiles()
+		if err != nil {
+			return "", err
+		}
+		go func(done chan error) {
+			done <- nil
+		}(s.done)
+		return abss[0], nil
+	}
+
+	ofs, abs, err := s.export()
 	if err != nil {
 		return
 	}
-	if of == nil {
+	if ofs == nil {
 		err = errors.New("output file is nil")
 		return
 	}
 
-	go func(combined chan result, done chan error, of *os.File) {
+	// The order is saved for the next build, see Options.Stable
+	var orderFile string
+	s.globMu.RLock()
+	order, patterns := s.paths, s.patterns
+	s.globMu.RUnlock()
+	s.optsMu.RLock()
+	if s.opts.Stable {
+		orderFile = orderPath(s.opts, patterns)
+	}
+	s.optsMu.RUnlock()
+
+	go func(combined chan result, done chan error, ofs []*os.File) {
 		// We're good for output file location, listen for combining success
 		result := <-combined
 		if result.err != nil {
+			closeFiles(ofs)
 			done <- err
 			return
 		}
-		err := writeToDisk(of, result.buf)
-		if err != nil {
-			done <- err
+		if len(result.bufs) != len(ofs) {
+			closeFiles(ofs)
+			done <- ErrSheetCount
 			return
 		}
+		digests := make([]sheetDigest, len(ofs))
+		for i, of := range ofs {
+			digests[i] = digest(result.bufs[i].Bytes())
+			err := writeToDisk(of, result.bufs[i])
+			of.Close()
+			if err != nil {
+				closeFiles(ofs[i+1:])
+				done <- err
+				return
+			}
+		}
+		// The manifest is only written once every sheet is
+		if manifest != nil {
+			err := writeManifest(manifestPath(abs), manifest, digests)
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+		if len(orderFile) > 0 {
+			if err := writeOrder(orderFile, order); err != nil {
+				done <- err
+				return
+			}
+		}
 		// succeeded in writing sprite
 		done <- nil
-	}(s.combined, s.done, of)
+	}(s.combined, s.done, ofs)
 
 	return
 }
@@ -592,6 +1061,19 @@ func (s *Sprite) Wait() error {
 
 var ErrFailedToWrite = errors.New("failed to write sprite to disk")
 
+// ErrSheetCount is returned by Wait when the number of sheets encoded
+// does not match the files created by Export.
+var ErrSheetCount = errors.New("encoded sheets do not match output files")
+
+// closeFiles closes every file opened in files
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		if f != nil {
+			f.Close()
+		}
+	}
+}
+
 func writeToDisk(of *os.File, buf *bytes.Buffer) error {
 	n, err := io.Copy(of, buf)
 	if err != nil {
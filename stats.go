@@ -0,0 +1,89 @@
+package spritewell
+
+import "time"
+
+// Stats reports how efficiently the images of a sprite fill its
+// sheets and how long it took to build them.
+type Stats struct {
+	// Area is the total pixels of every sheet
+	Area int
+	// UsedArea is the pixels covered by images, duplicates are
+	// counted once
+	UsedArea int
+	// Fill is UsedArea / Area
+	Fill float64
+	// PaddingArea approximates the pixels spent on padding and margin
+	PaddingArea int
+
+	// EncodedSize is the size in bytes of the encoded PNG sheets. It
+	// is zero until the sheets have been combined.
+	EncodedSize int
+	Images      []ImageStats
+
+	DecodeTime, EncodeTime time.Duration
+}
+
+// ImageStats reports the contribution of a single image
+type ImageStats struct {
+	Path          string
+	Width, Height int
+	// Bytes estimates the share of EncodedSize taken by the image
+	// from its area. Duplicates of another image contribute nothing.
+	Bytes int
+}
+
+// Stats returns the efficiency statistics of the sprite. Call Wait
+// first to include the encoded size and timing.
+func (l *Sprite) Stats() Stats {
+	l.optsMu.RLock()
+	pack := l.opts.Pack
+	l.optsMu.RUnlock()
+	lay := l.packLayout(pack)
+
+	l.statsMu.Lock()
+	st := Stats{
+		EncodedSize: l.encodedSize,
+		DecodeTime:  l.decodeTime,
+		EncodeTime:  l.encodeTime,
+	}
+	l.statsMu.Unlock()
+	if lay.err != nil {
+		return st
+	}
+
+	opts := &lay.opts
+	gap := opts.Padding
+	sideX := opts.PaddingLeft + opts.PaddingRight
+	sideY := opts.PaddingTop + opts.PaddingBottom
+	for _, dims := range lay.sheetDims {
+		st.Area += dims.X * dims.Y
+		inner := Pos{dims.X - 2*opts.Margin, dims.Y - 2*opts.Margin}
+		st.PaddingArea += dims.X*dims.Y - inner.X*inner.Y
+	}
+
+	paths := l.Paths()
+	st.Images = make([]ImageStats, len(lay.canon))
+	areas := make([]int, len(lay.canon))
+	for i, c := range lay.canon {
+		w, h := l.ImageWidth(i), l.ImageHeight(i)
+		st.Images[i] = ImageStats{Width: w, Height: h}
+		if i < len(paths) {
+			st.Images[i].Path = paths[i]
+		}
+		if c != i {
+			continue
+		}
+		areas[i] = w * h
+		st.UsedArea += areas[i]
+		st.PaddingArea += (w+sideX+gap)*(h+sideY+gap) - w*h
+	}
+	if st.Area > 0 {
+		st.Fill = float64(st.UsedArea) / float64(st.Area)
+	}
+	if st.UsedArea > 0 {
+		for i := range st.Images {
+			st.Images[i].Bytes = st.EncodedSize * areas[i] / st.UsedArea
+		}
+	}
+	return st
+}
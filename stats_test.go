@@ -0,0 +1,51 @@
+package spritewell
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	tmp := setupTemp("TestStats")
+	defer tmp.Close()
+	imgs := New(&Options{
+		GenImgDir: tmp.Image,
+		BuildDir:  tmp.Build,
+		Padding:   10,
+	})
+	if err := imgs.Decode("test/139.jpg", "test/140.jpg"); err != nil {
+		t.Fatal(err)
+	}
+
+	st := imgs.Stats()
+	if e := 96 * 289; st.Area != e {
+		t.Errorf("got: %d wanted: %d", st.Area, e)
+	}
+	if e := 96*139 + 96*140; st.UsedArea != e {
+		t.Errorf("got: %d wanted: %d", st.UsedArea, e)
+	}
+	if st.Fill <= 0.9 || st.Fill >= 1 {
+		t.Errorf("got: %f wanted fill between 0.9 and 1", st.Fill)
+	}
+	if e := 2; len(st.Images) != e {
+		t.Fatalf("got: %d wanted: %d", len(st.Images), e)
+	}
+	if e := "test/140.jpg"; st.Images[1].Path != e {
+		t.Errorf("got: %s wanted: %s", st.Images[1].Path, e)
+	}
+
+	if _, err := imgs.Export(); err != nil {
+		t.Fatal(err)
+	}
+	if err := imgs.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	st = imgs.Stats()
+	if st.EncodedSize == 0 {
+		t.Errorf("encoded size not recorded")
+	}
+	if st.DecodeTime == 0 || st.EncodeTime == 0 {
+		t.Errorf("timings not recorded: %v %v", st.DecodeTime, st.EncodeTime)
+	}
+	sum := st.Images[0].Bytes + st.Images[1].Bytes
+	if sum > st.EncodedSize || sum < st.EncodedSize-2 {
+		t.Errorf("got: %d wanted: %d", sum, st.EncodedSize)
+	}
+}
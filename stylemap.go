@@ -0,0 +1,127 @@
+package spritewell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// ErrUnknownSyntax is returned by WriteMap for a Syntax other than
+// SCSS, Less and Stylus.
+var ErrUnknownSyntax = errors.New("unknown stylesheet syntax")
+
+// Syntax is the stylesheet language written by WriteMap
+type Syntax int
+
+const (
+	SCSS Syntax = iota
+	Less
+	Stylus
+)
+
+// mapEntry is the data of a single image in WriteMap
+type mapEntry struct {
+	name, url           string
+	x, y, width, height string
+}
+
+// mapEntries returns the entries of every image in Paths order, see
+// styleNames for the errors returned.
+func (l *Sprite) mapEntries() ([]mapEntry, error) {
+	names, err := l.styleNames()
+	if err != nil {
+		return nil, err
+	}
+	opaths, err := l.OutputPaths()
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]mapEntry, len(names))
+	for i, name := range names {
+		pos := l.GetPack(i)
+		entries[i] = mapEntry{
+			name:   name,
+			url:    filepath.ToSlash(opaths[l.Sheet(i)]),
+			x:      px(pos.X),
+			y:      px(pos.Y),
+			width:  px(l.ImageWidth(i)),
+			height: px(l.ImageHeight(i)),
+		}
+	}
+	return entries, nil
+}
+
+// WriteMap writes the position, size and url of every image as a map
+// in the stylesheet language syntax, followed by a mixin applying one
+// entry. The map and mixin are both called name, default "sprite".
+//
+// SCSS is a map $name of the image names to (x, y, width, height,
+// url) with the mixin @include name(image). Less has no maps, there
+// every value is a variable @name-image-x and the mixin is
+// .name(image). Stylus is a hash $name with the mixin name(image).
+// It returns the same errors as WriteCSS.
+func (l *Sprite) WriteMap(w io.Writer, syntax Syntax, name string) error {
+	if len(name) == 0 {
+		name = "sprite"
+	}
+	entries, err := l.mapEntries()
+	if err != nil {
+		return err
+	}
+	bw := bufio.NewWriter(w)
+	switch syntax {
+	case SCSS:
+		fmt.Fprintf(bw, "$%s: (\n", name)
+		for _, e := range entries {
+			fmt.Fprintf(bw, "  \"%s\": (x: %s, y: %s, width: %s, "+
+				"height: %s, url: \"%s\"),\n",
+				e.name, e.x, e.y, e.width, e.height, e.url)
+		}
+		fmt.Fprintf(bw, ");\n\n"+
+			"@mixin %[1]s($name, $map: $%[1]s) {\n"+
+			"  $entry: map-get($map, $name);\n"+
+			"  width: map-get($entry, width);\n"+
+			"  height: map-get($entry, height);\n"+
+			"  background: url(map-get($entry, url)) "+
+			"(0 - map-get($entry, x)) (0 - map-get($entry, y)) no-repeat;\n"+
+			"}\n", name)
+	case Less:
+		for _, e := range entries {
+			v := name + "-" + e.name
+			fmt.Fprintf(bw, "@%s-x: %s;\n", v, e.x)
+			fmt.Fprintf(bw, "@%s-y: %s;\n", v, e.y)
+			fmt.Fprintf(bw, "@%s-width: %s;\n", v, e.width)
+			fmt.Fprintf(bw, "@%s-height: %s;\n", v, e.height)
+			fmt.Fprintf(bw, "@%s-url: \"%s\";\n", v, e.url)
+		}
+		fmt.Fprintf(bw, "\n.%[1]s(@name) {\n"+
+			"  @x: \"%[1]s-@{name}-x\";\n"+
+			"  @y: \"%[1]s-@{name}-y\";\n"+
+			"  @width: \"%[1]s-@{name}-width\";\n"+
+			"  @height: \"%[1]s-@{name}-height\";\n"+
+			"  @url: \"%[1]s-@{name}-url\";\n"+
+			"  width: @@width;\n"+
+			"  height: @@height;\n"+
+			"  background: url(@@url) (0 - @@x) (0 - @@y) no-repeat;\n"+
+			"}\n", name)
+	case Stylus:
+		fmt.Fprintf(bw, "$%s = {\n", name)
+		for _, e := range entries {
+			fmt.Fprintf(bw, "  '%s': { x: %s, y: %s, width: %s, "+
+				"height: %s, url: '%s' }\n",
+				e.name, e.x, e.y, e.width, e.height, e.url)
+		}
+		fmt.Fprintf(bw, "}\n\n"+
+			"%[1]s(name, map = $%[1]s)\n"+
+			"  entry = map[name]\n"+
+			"  width entry.width\n"+
+			"  height entry.height\n"+
+			"  background url(entry.url) (0 - entry.x) (0 - entry.y) no-repeat\n",
+			name)
+	default:
+		return ErrUnknownSyntax
+	}
+	return bw.Flush()
+}
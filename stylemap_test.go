@@ -0,0 +1,57 @@
+package spritewell
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWriteMap(t *testing.T) {
+	imgs := New(&Options{
+		BuildDir:  "test/build",
+		GenImgDir: "test/build/img",
+	})
+	if err := imgs.Decode("test/139.png", "test/140.png"); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := imgs.OutputPath()
+
+	tests := []struct {
+		syntax Syntax
+		want   []string
+	}{
+		{SCSS, []string{
+			"$icons: (\n",
+			`"140": (x: 0, y: 139px, width: 96px, height: 140px, url: "` +
+				path + `"),`,
+			"@mixin icons($name, $map: $icons) {",
+		}},
+		{Less, []string{
+			"@icons-140-y: 139px;\n",
+			`@icons-140-url: "` + path + `";`,
+			".icons(@name) {",
+		}},
+		{Stylus, []string{
+			"$icons = {\n",
+			"'140': { x: 0, y: 139px, width: 96px, height: 140px, url: '" +
+				path + "' }",
+			"icons(name, map = $icons)\n",
+		}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := imgs.WriteMap(&buf, tt.syntax, "icons"); err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range tt.want {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("got: %s wanted: %s", buf.String(), e)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := imgs.WriteMap(&buf, Syntax(9), ""); err != ErrUnknownSyntax {
+		t.Errorf("got: %v wanted: %v", err, ErrUnknownSyntax)
+	}
+}
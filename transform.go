@@ -0,0 +1,188 @@
+package spritewell
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Transform changes an image before it is packed. The steps are
+// applied in the order of the fields. They are set in ImageOptions or
+// as a query suffix of the pattern passed to Decode, see
+// cutDirectives.
+type Transform struct {
+	// Crop the image to this rectangle of the source, the zero value
+	// keeps the whole image
+	Crop image.Rectangle
+	// Scale the image by this factor with Options.Filter, 0 keeps the
+	// size
+	Scale float64
+	// Rotate the image clockwise by a multiple of 90 degrees
+	Rotate int
+	// FlipH mirrors the image horizontally, FlipV vertically
+	FlipH, FlipV bool
+}
+
+// directives are the keys cutDirectives accepts
+var directives = map[string]bool{
+	"scale": true, "rotate": true, "flip": true, "crop": true,
+	"name": true, "variant": true,
+}
+
+// cutDirectives splits a pattern like "arrow.png?scale=0.5&flip=h"
+// into the glob and its directives. The suffix after the last "?" is
+// only cut if it is made of key=value pairs of known directives, so
+// "?" still matches any character in globs like "icon?.png".
+func cutDirectives(pattern string) (string, url.Values) {
+	i := strings.LastIndex(pattern, "?")
+	if i < 0 {
+		return pattern, nil
+	}
+	raw := pattern[i+1:]
+	for _, pair := range strings.Split(raw, "&") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || !directives[kv[0]] {
+			return pattern, nil
+		}
+	}
+	query, err := url.ParseQuery(raw)
+	if err != nil {
+		return pattern, nil
+	}
+	return pattern[:i], query
+}
+
+// directiveRel returns the name of an image decoded with directives.
+// The directive name replaces the base name of rel, otherwise the
+// directives are appended to keep the name unique, ie. arrow.png with
+// flip=h is arrow-flip-h.png. A density suffix is kept last, so high
+// density variants still match their 1x image.
+func directiveRel(rel string, query url.Values) string {
+	rel, n := densityOf(rel)
+	ext := filepath.Ext(rel)
+	name := strings.TrimSuffix(rel, ext)
+	if v := query.Get("name"); len(v) > 0 {
+		name = filepath.Join(filepath.Dir(rel), v)
+	} else {
+		keys := make([]string, 0, len(query))
+		for key := range query {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, v := range query[key] {
+				name += "-" + key + "-" + strings.Map(func(r rune) rune {
+					if r == ',' || r == '/' || r == '\\' {
+						return '-'
+					}
+					return r
+				}, v)
+			}
+		}
+	}
+	if n > 1 {
+		name += "@" + strconv.Itoa(n) + "x"
+	}
+	return name + ext
+}
+
+// parse applies the directives of query to t. Supported are
+// scale=0.5, rotate=90, flip=h, flip=v or flip=hv and crop=x,y,w,h.
+// name is handled by directiveRel and variant by Decode.
+func (t *Transform) parse(query url.Values) error {
+	for key := range query {
+		v := query.Get(key)
+		var err error
+		switch key {
+		case "name", "variant":
+		case "scale":
+			t.Scale, err = strconv.ParseFloat(v, 64)
+			if err == nil && t.Scale <= 0 {
+				err = errors.New("scale must be positive")
+			}
+		case "rotate":
+			t.Rotate, err = strconv.Atoi(v)
+			if err == nil && t.Rotate%90 != 0 {
+				err = errors.New("rotate must be a multiple of 90")
+			}
+		case "flip":
+			t.FlipH = strings.Contains(v, "h")
+			t.FlipV = strings.Contains(v, "v")
+			if strings.Trim(v, "hv") != "" {
+				err = errors.New("flip must be h, v or hv")
+			}
+		case "crop":
+			var r [4]int
+			fields := strings.Split(v, ",")
+			if len(fields) != 4 {
+				err = errors.New("crop must be x,y,w,h")
+			}
+			for i := 0; i < len(fields) && err == nil; i++ {
+				r[i], err = strconv.Atoi(fields[i])
+			}
+			t.Crop = image.Rect(r[0], r[1], r[0]+r[2], r[1]+r[3])
+		default:
+			err = errors.New("unknown directive")
+		}
+		if err != nil {
+			return fmt.Errorf("directive %s=%s: %s", key, v, err)
+		}
+	}
+	return nil
+}
+
+// apply transforms img, a variant at density n of the image t was
+// set for. Crop is multiplied by n.
+func (t Transform) apply(img image.Image, n int, f Filter) image.Image {
+	b := img.Bounds()
+	if !t.Crop.Empty() {
+		r := image.Rect(t.Crop.Min.X*n, t.Crop.Min.Y*n,
+			t.Crop.Max.X*n, t.Crop.Max.Y*n).Add(b.Min).Intersect(b)
+		if s, ok := img.(subImager); ok {
+			img = s.SubImage(r)
+		} else {
+			m := image.NewRGBA(r)
+			draw.Draw(m, r, img, r.Min, draw.Src)
+			img = m
+		}
+		b = img.Bounds()
+	}
+	if t.Scale > 0 && t.Scale != 1 {
+		w := int(math.Max(1, math.Round(float64(b.Dx())*t.Scale)))
+		h := int(math.Max(1, math.Round(float64(b.Dy())*t.Scale)))
+		img = resample(img, w, h, f)
+	}
+	for i := 0; i < (t.Rotate/90%4+4)%4; i++ {
+		img = rotate90(img)
+	}
+	if t.FlipH || t.FlipV {
+		img = flip(img, t.FlipH, t.FlipV)
+	}
+	return img
+}
+
+// flip returns a copy of img mirrored horizontally and or vertically
+func flip(img image.Image, h, v bool) *image.RGBA {
+	b := img.Bounds()
+	m := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			dx, dy := x-b.Min.X, y-b.Min.Y
+			if h {
+				dx = b.Max.X - 1 - x
+			}
+			if v {
+				dy = b.Max.Y - 1 - y
+			}
+			m.Set(dx, dy, img.At(x, y))
+		}
+	}
+	return m
+}
@@ -0,0 +1,183 @@
+package spritewell
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTransformParse(t *testing.T) {
+	q, _ := url.ParseQuery("scale=0.5&rotate=270&flip=hv&crop=1,2,3,4")
+	var tr Transform
+	if err := tr.parse(q); err != nil {
+		t.Fatal(err)
+	}
+	e := Transform{
+		Crop:   image.Rect(1, 2, 4, 6),
+		Scale:  0.5,
+		Rotate: 270,
+		FlipH:  true,
+		FlipV:  true,
+	}
+	if tr != e {
+		t.Errorf("got: %+v wanted: %+v", tr, e)
+	}
+
+	for _, bad := range []string{"scale=-1", "rotate=45", "flip=x",
+		"crop=1,2", "spin=1"} {
+		q, _ := url.ParseQuery(bad)
+		if err := new(Transform).parse(q); err == nil {
+			t.Errorf("%s parsed without error", bad)
+		}
+	}
+}
+
+func TestTransformApply(t *testing.T) {
+	red := color.RGBA{255, 0, 0, 255}
+	m := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	m.Set(0, 0, red)
+
+	img := Transform{FlipH: true}.apply(m, 1, Nearest)
+	if img.At(3, 0) != red {
+		t.Errorf("got: %v wanted: %v", img.At(3, 0), red)
+	}
+	img = Transform{FlipV: true, Rotate: 180}.apply(m, 1, Nearest)
+	if img.At(3, 0) != red {
+		t.Errorf("got: %v wanted: %v", img.At(3, 0), red)
+	}
+	img = Transform{Rotate: 90}.apply(m, 1, Nearest)
+	if e := image.Rect(0, 0, 2, 4); img.Bounds() != e {
+		t.Errorf("got: %v wanted: %v", img.Bounds(), e)
+	}
+	img = Transform{Crop: image.Rect(0, 0, 1, 1), Scale: 2}.apply(m, 1, Nearest)
+	if e := image.Rect(0, 0, 2, 2); img.Bounds() != e {
+		t.Errorf("got: %v wanted: %v", img.Bounds(), e)
+	}
+	if img.At(1, 1) != red {
+		t.Errorf("got: %v wanted: %v", img.At(1, 1), red)
+	}
+}
+
+func TestSpriteDirectives(t *testing.T) {
+	imgs := New(&Options{
+		Images: map[string]ImageOptions{
+			"140": {Transform: Transform{Scale: 0.5}},
+		},
+	})
+	err := imgs.Decode("test/139.png", "test/139.png?rotate=90&name=turned",
+		"test/140.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 3; imgs.Len() != e {
+		t.Fatalf("got: %d wanted: %d", imgs.Len(), e)
+	}
+	pos := imgs.Lookup("turned")
+	if pos < 0 {
+		t.Fatalf("turned not found in %v", imgs.Paths())
+	}
+	if w, h := imgs.ImageWidth(pos), imgs.ImageHeight(pos); w != 139 || h != 96 {
+		t.Errorf("got: %dx%d wanted: 139x96", w, h)
+	}
+	pos = imgs.Lookup("140")
+	if w, h := imgs.ImageWidth(pos), imgs.ImageHeight(pos); w != 48 || h != 70 {
+		t.Errorf("got: %dx%d wanted: 48x70", w, h)
+	}
+
+	if err := imgs.Decode("test/139.png?scale=x"); err == nil {
+		t.Error("invalid directive decoded")
+	}
+}
+
+func TestDirectiveRel(t *testing.T) {
+	tests := []struct {
+		rel, query, name string
+	}{
+		{"arrow.png", "flip=h", "arrow-flip-h.png"},
+		{"ui/arrow@2x.png", "rotate=90&flip=v", "ui/arrow-flip-v-rotate-90@2x.png"},
+		{"ui/arrow@2x.png", "flip=h&name=back", "ui/back@2x.png"},
+		{"arrow.png", "crop=0,0,4,4", "arrow-crop-0-0-4-4.png"},
+	}
+	for _, tt := range tests {
+		query, _ := url.ParseQuery(tt.query)
+		name := filepath.ToSlash(directiveRel(filepath.FromSlash(tt.rel), query))
+		if name != tt.name {
+			t.Errorf("got: %s wanted: %s", name, tt.name)
+		}
+	}
+}
+
+func TestSpriteDirectivesCopy(t *testing.T) {
+	imgs := New(&Options{
+		BuildDir:  "test/build",
+		GenImgDir: "test/build/img",
+		Images: map[string]ImageOptions{
+			"140": {Align: AlignRight},
+		},
+	})
+	err := imgs.Decode("test/139.png", "test/140.png",
+		"test/140.png?scale=0.5&flip=h")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; imgs.Lookup("140") != e {
+		t.Errorf("got: %d wanted: %d", imgs.Lookup("140"), e)
+	}
+	for _, name := range []string{"140-flip-h-scale-0.5",
+		"test/140.png?scale=0.5&flip=h", "test/140.png?flip=h&scale=0.5"} {
+		if e := 2; imgs.Lookup(name) != e {
+			t.Errorf("%s got: %d wanted: %d", name, imgs.Lookup(name), e)
+		}
+	}
+	if e := "test/140-flip-h-scale-0.5.png"; imgs.Paths()[2] != e {
+		t.Errorf("got: %s wanted: %s", imgs.Paths()[2], e)
+	}
+	// The copy is configured like its original
+	if e := (Pos{48, 279}); imgs.GetPack(2) != e {
+		t.Errorf("got: %v wanted: %v", imgs.GetPack(2), e)
+	}
+
+	var buf bytes.Buffer
+	if err := imgs.WriteCSS(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; strings.Count(buf.String(), ".sprite-140 {") != e {
+		t.Errorf("got: %s wanted one .sprite-140 rule", buf.String())
+	}
+	if !strings.Contains(buf.String(), ".sprite-140-flip-h-scale-0-5 {") {
+		t.Errorf("got: %s wanted a rule for the flipped copy", buf.String())
+	}
+}
+
+func TestCutDirectives(t *testing.T) {
+	tests := []struct {
+		pattern, glob string
+		directives    bool
+	}{
+		{"arrow.png?flip=h&name=back", "arrow.png", true},
+		{"icon?.png", "icon?.png", false},
+		{"icon?.png?rotate=90", "icon?.png", true},
+		{"dir?/icon.png", "dir?/icon.png", false},
+		{"icon.png?spin=1", "icon.png?spin=1", false},
+		{"icon.png?flip", "icon.png?flip", false},
+	}
+	for _, tt := range tests {
+		glob, query := cutDirectives(tt.pattern)
+		if glob != tt.glob || (query != nil) != tt.directives {
+			t.Errorf("%s got: %s %v wanted: %s", tt.pattern, glob, query,
+				tt.glob)
+		}
+	}
+
+	imgs := New(nil)
+	if err := imgs.Decode("test/13?.png"); err != nil {
+		t.Fatal(err)
+	}
+	if e := 1; imgs.Len() != e || imgs.Lookup("139") != 0 {
+		t.Errorf("got: %v wanted: [test/139.png]", imgs.Paths())
+	}
+}
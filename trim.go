@@ -0,0 +1,91 @@
+package spritewell
+
+import (
+	"image"
+	"image/draw"
+)
+
+type subImager interface {
+	SubImage(r image.Rectangle) image.Image
+}
+
+// trim removes fully transparent rows and columns from the edges of
+// img. The offset of the trimmed image inside img is returned. A fully
+// transparent image is trimmed to its top left pixel.
+func trim(img image.Image) (image.Image, Pos) {
+	b := img.Bounds()
+	r := image.Rectangle{}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if _, _, _, a := img.At(x, y).RGBA(); a == 0 {
+				continue
+			}
+			if r.Empty() {
+				r = image.Rect(x, y, x+1, y+1)
+				continue
+			}
+			r = r.Union(image.Rect(x, y, x+1, y+1))
+		}
+	}
+	if r.Empty() {
+		if b.Empty() {
+			return img, Pos{}
+		}
+		r = image.Rect(b.Min.X, b.Min.Y, b.Min.X+1, b.Min.Y+1)
+	}
+	if r == b {
+		return img, Pos{}
+	}
+
+	offset := Pos{r.Min.X - b.Min.X, r.Min.Y - b.Min.Y}
+	if s, ok := img.(subImager); ok {
+		return s.SubImage(r), offset
+	}
+	m := image.NewRGBA(r)
+	draw.Draw(m, r, img, r.Min, draw.Src)
+	return m, offset
+}
+
+// SourceWidth returns the width of the image at pos before it was
+// trimmed. See Options.Trim
+func (l *Sprite) SourceWidth(pos int) int {
+	l.goImagesMu.RLock()
+	defer l.goImagesMu.RUnlock()
+	if pos < 0 || pos >= len(l.sources) {
+		return -1
+	}
+	return l.sources[pos].X
+}
+
+// SourceHeight returns the height of the image at pos before it was
+// trimmed. See Options.Trim
+func (l *Sprite) SourceHeight(pos int) int {
+	l.goImagesMu.RLock()
+	defer l.goImagesMu.RUnlock()
+	if pos < 0 || pos >= len(l.sources) {
+		return -1
+	}
+	return l.sources[pos].Y
+}
+
+// TrimOffset returns the position of the trimmed image at pos inside
+// the source image. CSS can compensate by adding it to X and Y.
+func (l *Sprite) TrimOffset(pos int) Pos {
+	l.goImagesMu.RLock()
+	defer l.goImagesMu.RUnlock()
+	if pos < 0 || pos >= len(l.offsets) {
+		return Pos{0, 0}
+	}
+	return l.offsets[pos]
+}
+
+// Trimmed reports whether transparent edges were removed from the
+// image at pos.
+func (l *Sprite) Trimmed(pos int) bool {
+	l.goImagesMu.RLock()
+	defer l.goImagesMu.RUnlock()
+	if pos < 0 || pos >= len(l.imgs) {
+		return false
+	}
+	return l.imgs[pos].Bounds().Size() != l.sources[pos]
+}
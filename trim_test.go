@@ -0,0 +1,56 @@
+package spritewell
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTrim(t *testing.T) {
+	m := image.NewNRGBA(image.Rect(0, 0, 10, 8))
+	m.Set(2, 3, color.NRGBA{A: 255})
+	m.Set(6, 4, color.NRGBA{A: 1})
+
+	img, offset := trim(m)
+	if e := image.Rect(2, 3, 7, 5); img.Bounds() != e {
+		t.Errorf("got: %v wanted: %v", img.Bounds(), e)
+	}
+	if e := (Pos{2, 3}); offset != e {
+		t.Errorf("got: %v wanted: %v", offset, e)
+	}
+
+	img, offset = trim(image.NewNRGBA(image.Rect(0, 0, 4, 4)))
+	if e := image.Rect(0, 0, 1, 1); img.Bounds() != e {
+		t.Errorf("got: %v wanted: %v", img.Bounds(), e)
+	}
+}
+
+func TestSpriteTrim(t *testing.T) {
+	imgs := New(&Options{Trim: true})
+	err := imgs.Decode("test/139.jpg", "test/trim/margin.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := 20; imgs.ImageWidth(1) != e {
+		t.Errorf("got: %d wanted: %d", imgs.ImageWidth(1), e)
+	}
+	if e := 10; imgs.ImageHeight(1) != e {
+		t.Errorf("got: %d wanted: %d", imgs.ImageHeight(1), e)
+	}
+	if e := 32; imgs.SourceWidth(1) != e {
+		t.Errorf("got: %d wanted: %d", imgs.SourceWidth(1), e)
+	}
+	if e := 24; imgs.SourceHeight(1) != e {
+		t.Errorf("got: %d wanted: %d", imgs.SourceHeight(1), e)
+	}
+	if e := (Pos{6, 4}); imgs.TrimOffset(1) != e {
+		t.Errorf("got: %v wanted: %v", imgs.TrimOffset(1), e)
+	}
+	if !imgs.Trimmed(1) || imgs.Trimmed(0) {
+		t.Errorf("got: %t %t wanted: true false",
+			imgs.Trimmed(1), imgs.Trimmed(0))
+	}
+	if e := (Pos{96, 149}); imgs.Dimensions() != e {
+		t.Errorf("got: %v wanted: %v", imgs.Dimensions(), e)
+	}
+}